@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -19,7 +21,10 @@ var rootCmd = &cobra.Command{
 	Short: "Check Sonarr/Radarr episodes/movies for low custom format scores",
 	Long:  `A microservice that checks Sonarr episodes and Radarr movies for low custom format scores and optionally triggers searches for better versions.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		app.RunOnce()
+		ctx, stop := shutdownContext()
+		defer stop()
+
+		app.RunOnce(ctx)
 	},
 }
 
@@ -28,38 +33,155 @@ var daemonCmd = &cobra.Command{
 	Short: "Run as a daemon with periodic checks",
 	Long:  `Run the score checker as a daemon that performs periodic checks at the configured interval.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Set up signal handling for graceful shutdown
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		go func() {
-			<-c
-			slog.Info("Received shutdown signal, exiting...")
-			os.Exit(0)
-		}()
-
-		app.RunDaemon()
+		ctx, stop := shutdownContext()
+		defer stop()
+
+		app.RunDaemon(ctx)
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long:  `Check the configuration for problems or show the settings score-checker would use.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:           "validate",
+	Short:         "Check the configuration for errors and likely mistakes",
+	Long:          `Check the config file, environment variables and flags, listing every error and warning instead of stopping at the first. Exits with a non-zero status if there are errors.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report := config.Validate()
+		out := cmd.OutOrStdout()
+		for _, message := range report.Errors {
+			fmt.Fprintf(out, "ERROR: %s\n", message)
+		}
+		for _, message := range report.Warnings {
+			fmt.Fprintf(out, "WARNING: %s\n", message)
+		}
+
+		if len(report.Errors) > 0 {
+			return fmt.Errorf("configuration has %d error(s) and %d warning(s)", len(report.Errors), len(report.Warnings))
+		}
+		fmt.Fprintf(out, "Configuration is valid (%d warning(s))\n", len(report.Warnings))
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:           "show",
+	Short:         "Show the effective configuration",
+	Long:          `Show the fully resolved configuration with secrets masked, and whether each value came from a flag, an environment variable, the config file or a default.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Show(cmd.OutOrStdout(), cmd.Flags()); err != nil {
+			return fmt.Errorf("invalid configuration, run 'config validate' for details:\n%w", err)
+		}
+		return nil
 	},
 }
 
+var checkConnectionCmd = &cobra.Command{
+	Use:           "check-connection",
+	Short:         "Check that every instance is reachable and accepts its API key",
+	Long:          `Call the system status and health endpoints of every configured instance and print a table of their versions, status, latency and health warnings. Exits with a non-zero status if any instance can't be reached or rejects its API key.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := shutdownContext()
+		defer stop()
+
+		return app.CheckConnections(ctx, cmd.OutOrStdout())
+	},
+}
+
+// shutdownContext returns a context that is canceled on SIGINT or SIGTERM.
+// Checks then stop and requests underway get the grace period to finish. A
+// second signal exits immediately. Calling the returned function cancels the
+// context without a signal, as on a normal exit.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default behavior so another signal kills the process
+		defer signal.Stop(signals)
+
+		select {
+		case <-signals:
+			slog.Info("Received shutdown signal, finishing requests underway...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// initConfig reads the configuration once the command line is parsed, so
+// --config can point at the config file
+func initConfig() {
+	path, _ := rootCmd.PersistentFlags().GetString("config")
+	config.InitWithConfig(path)
+}
+
 func init() {
+	cobra.OnInitialize(initConfig)
+
 	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(checkConnectionCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
 
 	// Add flags
+	rootCmd.PersistentFlags().String("config", "", "Config file, or a directory of config files merged in lexical order (env: SCORECHECK_CONFIG)")
 	rootCmd.PersistentFlags().Bool("triggersearch", false, "Trigger searches for better versions")
 	rootCmd.PersistentFlags().Int("batchsize", 5, "Number of items to check per run")
 	rootCmd.PersistentFlags().String("interval", "1h", "Interval for daemon mode (e.g., 30m, 1h, 2h30m)")
 	rootCmd.PersistentFlags().String("loglevel", "INFO", "Log level (ERROR, INFO, DEBUG, VERBOSE)")
+	rootCmd.PersistentFlags().Int("threshold", 0, "Custom format scores below this are considered low")
+	rootCmd.PersistentFlags().String("mode", "threshold", "What counts as a low score (threshold, minformatscore, cutoff)")
+	rootCmd.PersistentFlags().String("cooldown", "0s", "Minimum time before searching the same item again (e.g., 24h)")
+	rootCmd.PersistentFlags().String("order", "default", "Which items to search first (default, lowest-score, oldest-file, newest, random, round-robin)")
+	rootCmd.PersistentFlags().Bool("monitoredonly", true, "Skip unmonitored series, seasons, episodes and movies")
+	rootCmd.PersistentFlags().Int("concurrency", 4, "Number of instances checked at once")
+	rootCmd.PersistentFlags().Int("workers", 4, "Number of concurrent item requests per instance")
+	rootCmd.PersistentFlags().Int("retryattempts", 3, "Attempts per API request before giving up (1 disables retries)")
+	rootCmd.PersistentFlags().String("retrydelay", "1s", "Backoff before the first retry, doubled for each one after")
+	rootCmd.PersistentFlags().String("retrymaxdelay", "30s", "Maximum backoff between retries, including Retry-After waits")
+	rootCmd.PersistentFlags().Float64("ratelimit", 0, "Maximum API requests per second to each instance (0 = unlimited)")
+	rootCmd.PersistentFlags().Int("rateburst", 5, "Requests that may be sent to an instance at once before the rate limit applies")
+	rootCmd.PersistentFlags().String("timeout", "30s", "Timeout for each API request, including reading the response (0 = none)")
+	rootCmd.PersistentFlags().String("graceperiod", "5s", "How long requests underway may take to finish when shutting down")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("triggersearch", rootCmd.PersistentFlags().Lookup("triggersearch"))
 	_ = viper.BindPFlag("batchsize", rootCmd.PersistentFlags().Lookup("batchsize"))
 	_ = viper.BindPFlag("interval", rootCmd.PersistentFlags().Lookup("interval"))
 	_ = viper.BindPFlag("loglevel", rootCmd.PersistentFlags().Lookup("loglevel"))
+	_ = viper.BindPFlag("threshold", rootCmd.PersistentFlags().Lookup("threshold"))
+	_ = viper.BindPFlag("mode", rootCmd.PersistentFlags().Lookup("mode"))
+	_ = viper.BindPFlag("cooldown", rootCmd.PersistentFlags().Lookup("cooldown"))
+	_ = viper.BindPFlag("order", rootCmd.PersistentFlags().Lookup("order"))
+	_ = viper.BindPFlag("monitoredonly", rootCmd.PersistentFlags().Lookup("monitoredonly"))
+	_ = viper.BindPFlag("concurrency", rootCmd.PersistentFlags().Lookup("concurrency"))
+	_ = viper.BindPFlag("workers", rootCmd.PersistentFlags().Lookup("workers"))
+	_ = viper.BindPFlag("retryattempts", rootCmd.PersistentFlags().Lookup("retryattempts"))
+	_ = viper.BindPFlag("retrydelay", rootCmd.PersistentFlags().Lookup("retrydelay"))
+	_ = viper.BindPFlag("retrymaxdelay", rootCmd.PersistentFlags().Lookup("retrymaxdelay"))
+	_ = viper.BindPFlag("ratelimit", rootCmd.PersistentFlags().Lookup("ratelimit"))
+	_ = viper.BindPFlag("rateburst", rootCmd.PersistentFlags().Lookup("rateburst"))
+	_ = viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	_ = viper.BindPFlag("graceperiod", rootCmd.PersistentFlags().Lookup("graceperiod"))
 }
 
 func main() {
-	config.Init()
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	config.CloseLog()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
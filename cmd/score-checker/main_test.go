@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -52,11 +57,26 @@ batchsize: 1
 			t.Errorf("expected daemon Use to be 'daemon', got '%s'", daemonCmd.Use)
 		}
 	}
+
+	if findCommand(rootCmd, "check-connection") == nil {
+		t.Error("check-connection command not found")
+	}
+
+	// Test that the config subcommands exist
+	configCmd := findCommand(rootCmd, "config")
+	if configCmd == nil {
+		t.Fatal("config command not found")
+	}
+	for _, name := range []string{"validate", "show"} {
+		if findCommand(configCmd, name) == nil {
+			t.Errorf("config %s command not found", name)
+		}
+	}
 }
 
 func TestCommandFlags(t *testing.T) {
 	// Test that required flags exist
-	flags := []string{"triggersearch", "batchsize", "interval"}
+	flags := []string{"triggersearch", "batchsize", "interval", "config"}
 
 	for _, flagName := range flags {
 		flag := rootCmd.PersistentFlags().Lookup(flagName)
@@ -141,3 +161,34 @@ func TestMainCommandExecution(t *testing.T) {
 		}
 	}
 }
+
+func TestShutdownContext(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	// A normal exit cancels the context without reporting a signal
+	ctx, stop := shutdownContext()
+	stop()
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be logged without a signal, got %q", buf.String())
+	}
+
+	// A signal cancels the context and is reported
+	ctx, stop = shutdownContext()
+	defer stop()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the context to be canceled")
+	}
+	if !strings.Contains(buf.String(), "Received shutdown signal") {
+		t.Errorf("expected the signal to be logged, got %q", buf.String())
+	}
+}
@@ -1,275 +1,529 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
 	"score-checker/internal/config"
 	"score-checker/internal/constants"
+	"score-checker/internal/lidarr"
 	"score-checker/internal/radarr"
+	"score-checker/internal/readarr"
 	"score-checker/internal/sonarr"
+	"score-checker/internal/state"
 	"score-checker/internal/types"
 )
 
-// findLowScoreEpisodes finds episodes with custom format scores below zero
-// and optionally triggers searches for better versions
-// batchSize limits how many episodes to process per run (0 = unlimited)
-func findLowScoreEpisodes(client *sonarr.Client, cfg types.Config, instanceName string) ([]types.LowScoreEpisode, error) {
-	// Get all series
-	series, err := client.GetSeries()
+// usesQualityProfiles reports whether a selection mode compares scores
+// against quality profile limits
+func usesQualityProfiles(mode string) bool {
+	return mode == constants.ModeMinFormatScore || mode == constants.ModeCutoffFormatScore
+}
+
+// loadQualityProfiles fetches quality profiles keyed by ID when the
+// instance's selection mode needs them
+func loadQualityProfiles(instance types.ServiceConfig, fetch func() ([]types.QualityProfile, error)) (map[int]types.QualityProfile, error) {
+	if !usesQualityProfiles(instance.Mode) {
+		return nil, nil
+	}
+
+	profiles, err := fetch()
 	if err != nil {
-		return nil, fmt.Errorf("getting series: %w", err)
+		return nil, fmt.Errorf("getting quality profiles: %w", err)
 	}
 
-	var lowScoreEpisodes []types.LowScoreEpisode
-	var episodesToSearch []int
+	byID := make(map[int]types.QualityProfile, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.ID] = profile
+	}
+	return byID, nil
+}
 
-	// Check each series
-	processedCount := 0
-	reachedLimit := false
-	for _, s := range series {
-		if reachedLimit {
-			break
-		}
+// scoreLimit returns the score below which a file is considered low and
+// whether it comes from a quality profile. Falls back to the instance
+// threshold if the profile is unknown.
+func scoreLimit(instance types.ServiceConfig, profiles map[int]types.QualityProfile, profileID int) (int, bool) {
+	profile, ok := profiles[profileID]
+	if !ok {
+		return instance.Threshold, false
+	}
 
-		slog.Debug(fmt.Sprintf("[%s] Checking series: %s (ID: %d)", instanceName, s.Title, s.ID))
+	if instance.Mode == constants.ModeCutoffFormatScore {
+		return profile.CutoffFormatScore, true
+	}
+	return profile.MinFormatScore, true
+}
 
-		episodes, err := client.GetEpisodes(s.ID)
-		if err != nil {
-			slog.Error(fmt.Sprintf("[%s] Warning: failed to get episodes for series %s: %v", instanceName, s.Title, err))
-			continue
-		}
+// describeLimit describes what counts as a low score for an instance
+func describeLimit(instance types.ServiceConfig) string {
+	switch instance.Mode {
+	case constants.ModeMinFormatScore:
+		return "below their quality profile's minimum format score"
+	case constants.ModeCutoffFormatScore:
+		return "below their quality profile's cutoff format score"
+	default:
+		return fmt.Sprintf("below %d", instance.Threshold)
+	}
+}
 
-		// Check each episode that has a file
-		for _, episode := range episodes {
-			if episode.HasFile && episode.EpisodeFile != nil {
-				if episode.EpisodeFile.CustomFormatScore < 0 {
-					lowScoreEpisodes = append(lowScoreEpisodes, types.LowScoreEpisode{
-						Series:            s,
-						Episode:           episode,
-						CustomFormatScore: episode.EpisodeFile.CustomFormatScore,
-					})
-
-					// Collect episode IDs for search if enabled
-					if cfg.TriggerSearch {
-						episodesToSearch = append(episodesToSearch, episode.ID)
-					}
+// stateKey identifies an instance's entries in the state store
+func stateKey(service, instanceName string) string {
+	return service + "/" + instanceName
+}
 
-					processedCount++
-					// Stop if we've reached the batch limit
-					if cfg.BatchSize > 0 && processedCount >= cfg.BatchSize {
-						slog.Info(fmt.Sprintf("[%s] Reached batch limit of %d episodes", instanceName, cfg.BatchSize))
-						reachedLimit = true
-						break
-					}
+// resumeRotation yields the library entries whose ID isn't in checked, as
+// they arrive from the stream returned by open. It then opens the stream
+// again and yields the checked entries, which start the next pass through
+// the library. The *arr applications don't guarantee the order they list
+// their library in, so progress is tracked by ID rather than by position.
+func resumeRotation[T any](open func() iter.Seq2[T, error], checked map[int]bool, id func(T) int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, wrapped := range []bool{false, true} {
+			if wrapped && len(checked) == 0 {
+				return
+			}
+			for item, err := range open() {
+				if err != nil {
+					yield(item, err)
+					return
+				}
+				if checked[id(item)] != wrapped {
+					continue
+				}
+				if !yield(item, nil) {
+					return
 				}
 			}
 		}
 	}
+}
 
-	// Trigger searches if enabled and we have episodes to search
-	if cfg.TriggerSearch && len(episodesToSearch) > 0 {
-		slog.Info(fmt.Sprintf("[%s] Triggering search for %d episode(s) with low scores...", instanceName, len(episodesToSearch)))
-
-		// Search in batches to avoid overwhelming the system
-		batchSize := constants.DefaultSearchBatchSize
-		for i := 0; i < len(episodesToSearch); i += batchSize {
-			end := min(i+batchSize, len(episodesToSearch))
-
-			batch := episodesToSearch[i:end]
-			resp, err := client.TriggerEpisodeSearch(batch)
-			if err != nil {
-				slog.Error(fmt.Sprintf("[%s] Warning: failed to trigger search for episodes %v: %v", instanceName, batch, err))
-				continue
-			}
+// searchedRecently reports whether a search for item id was triggered
+// less than cooldown ago
+func searchedRecently(store *state.Store, key string, id int, cooldown time.Duration) bool {
+	if cooldown <= 0 {
+		return false
+	}
+	searchedAt, ok := store.LastSearched(key, id)
+	return ok && time.Since(searchedAt) < cooldown
+}
 
-			slog.Info(fmt.Sprintf("[%s] Search triggered for batch: %v (Command ID: %d, Status: %s)",
-				instanceName, batch, resp.ID, resp.Status))
+// episodeMonitored reports whether an episode, its season and its series
+// are all monitored
+func episodeMonitored(series types.Series, episode types.Episode) bool {
+	if !series.Monitored || !episode.Monitored {
+		return false
+	}
+	for _, season := range series.Seasons {
+		if season.SeasonNumber == episode.SeasonNumber {
+			return season.Monitored
 		}
 	}
-
-	return lowScoreEpisodes, nil
+	return true
 }
 
-// findLowScoreMovies finds movies with custom format scores below zero
-// and optionally triggers searches for better versions
-func findLowScoreMovies(client *radarr.Client, cfg types.Config, instanceName string) ([]types.LowScoreMovie, error) {
-	// Get all movies
-	movies, err := client.GetMovies()
+// findLowScore finds items with custom format scores below the instance's
+// limit and optionally triggers searches for better versions.
+// cfg.BatchSize limits how many items to process per run (0 = unlimited).
+// Each run first checks the library entries the previous runs haven't reached
+// yet, unless an order strategy picks the best candidates from the whole library.
+// Once ctx is canceled no more entries are checked and no more searches are
+// triggered, and the progress of the interrupted run isn't kept.
+func findLowScore[P, T any](ctx context.Context, svc service[P, T], cfg types.Config, instance types.ServiceConfig, store *state.Store, logger *slog.Logger) ([]T, error) {
+	instanceName := instance.Name
+	kind := svc.kind()
+
+	profiles, err := loadQualityProfiles(instance, func() ([]types.QualityProfile, error) {
+		return svc.GetQualityProfiles(ctx)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("getting movies: %w", err)
+		return nil, err
 	}
 
-	var lowScoreMovies []types.LowScoreMovie
-	var moviesToSearch []int
+	tags, err := loadTagFilter(instance, func() ([]types.Tag, error) {
+		return svc.GetTags(ctx)
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check each movie that has a file
-	processedCount := 0
-	for _, movie := range movies {
-		slog.Debug(fmt.Sprintf("[%s] Checking movie: %s (%d)", instanceName, movie.Title, movie.Year))
+	// Without an order strategy the scan stops as soon as the batch is full,
+	// otherwise every candidate is collected and sorted first
+	stopEarly := !sortsCandidates(instance.Order)
+
+	// Continue where the previous run stopped, skipping the items it already
+	// found in the entry it stopped in. Runs that sort the candidates check
+	// the whole library each time, so they have nothing to continue.
+	key := stateKey(kind.key, instanceName)
+	checked := make(map[int]bool)
+	partialID, found := 0, make(map[int]bool)
+	if stopEarly {
+		checked = store.Checked(key)
+		partialID, found = store.Partial(key)
+	}
+	if len(checked) > 0 {
+		logger.Debug(fmt.Sprintf("[%s] Resuming with %d %s already checked", instanceName, len(checked), kind.parents))
+	}
+	if instance.Cooldown > 0 {
+		store.PruneSearches(key, time.Now().Add(-instance.Cooldown))
+	}
 
-		if movie.HasFile && movie.MovieFile != nil {
-			if movie.MovieFile.CustomFormatScore < 0 {
-				lowScoreMovies = append(lowScoreMovies, types.LowScoreMovie{
-					Movie:             movie,
-					CustomFormatScore: movie.MovieFile.CustomFormatScore,
-				})
+	var lowScoreItems []T
 
-				// Collect movie IDs for search if enabled
-				if cfg.TriggerSearch {
-					moviesToSearch = append(moviesToSearch, movie.ID)
-				}
+	// The library is read as a stream. Items are fetched on the instance's
+	// workers, everything else (including logging) stays in library order.
+	type fetched struct {
+		items []T
+		err   error
+	}
+	parents := resumeRotation(func() iter.Seq2[P, error] { return svc.all(ctx) }, checked, func(p P) int {
+		return svc.describeParent(p).target.ID
+	})
+	wanted := func(p P) bool {
+		return skipParent(kind, instance, tags, svc.describeParent(p)).message == ""
+	}
+	ahead := startLookahead(ctx, parents, instance.Workers, wanted, func(ctx context.Context, p P) fetched {
+		limit, _ := scoreLimit(instance, profiles, svc.describeParent(p).qualityProfileID)
+		items, err := svc.lowScoreItems(ctx, p, limit)
+		return fetched{items: items, err: err}
+	})
+	defer ahead.stop()
+
+	// Check each library entry
+	cooldownSkipped := 0
+	unmonitoredParents := 0
+	unmonitoredSkipped := 0
+	reachedLimit := false
+	stoppedIn := 0
+	var processed, wrapped, foundInEntry []int
+	for !reachedLimit {
+		entry, ok := ahead.next()
+		if !ok {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if entry.err != nil {
+			return nil, fmt.Errorf("getting %s: %w", kind.parents, entry.err)
+		}
+		parent := svc.describeParent(entry.parent)
+		if checked[parent.target.ID] {
+			wrapped = append(wrapped, parent.target.ID)
+		} else {
+			processed = append(processed, parent.target.ID)
+		}
+
+		if entry.result == nil {
+			skip := skipParent(kind, instance, tags, parent)
+			logger.Debug(fmt.Sprintf("[%s] %s", instanceName, skip.message))
+			if skip.unmonitored {
+				unmonitoredParents++
+			}
+			continue
+		}
+
+		if _, ok := scoreLimit(instance, profiles, parent.qualityProfileID); !ok && usesQualityProfiles(instance.Mode) {
+			logger.Debug(fmt.Sprintf("[%s] Unknown quality profile %d, using threshold %d", instanceName, parent.qualityProfileID, instance.Threshold))
+		}
+		logger.Debug(fmt.Sprintf("[%s] Checking %s: %s (ID: %d)", instanceName, kind.parent, parent.label, parent.target.ID))
+
+		result := ahead.get(entry)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if result.err != nil {
+			logger.Error(fmt.Sprintf("[%s] Warning: failed to check %s %s: %v", instanceName, kind.parent, parent.label, result.err))
+			continue
+		}
+
+		entryStart := len(lowScoreItems)
+		for i, it := range result.items {
+			item := svc.describeItem(it)
+
+			if parent.target.ID == partialID && found[item.id] {
+				logger.Debug(fmt.Sprintf("[%s] Skipping %s: already found in this pass", instanceName, item.label))
+				continue
+			}
+
+			if instance.MonitoredOnly && !item.monitored {
+				logger.Debug(fmt.Sprintf("[%s] Skipping unmonitored %s %s", instanceName, kind.item, item.label))
+				unmonitoredSkipped++
+				continue
+			}
+
+			if searchedRecently(store, key, item.id, instance.Cooldown) {
+				logger.Debug(fmt.Sprintf("[%s] Skipping %s: searched within the last %v", instanceName, item.label, instance.Cooldown))
+				cooldownSkipped++
+				continue
+			}
+
+			lowScoreItems = append(lowScoreItems, it)
 
-				processedCount++
-				// Stop if we've reached the batch limit
-				if cfg.BatchSize > 0 && processedCount >= cfg.BatchSize {
-					slog.Info(fmt.Sprintf("[%s] Reached batch limit of %d movies", instanceName, cfg.BatchSize))
-					break
+			// Stop if we've reached the batch limit
+			if stopEarly && cfg.BatchSize > 0 && len(lowScoreItems) >= cfg.BatchSize {
+				reachedLimit = true
+				if i < len(result.items)-1 {
+					stoppedIn = parent.target.ID
+					for _, it := range lowScoreItems[entryStart:] {
+						foundInEntry = append(foundInEntry, svc.describeItem(it).id)
+					}
 				}
+				break
 			}
 		}
 	}
+	ahead.stop()
+
+	if unmonitoredParents > 0 {
+		logger.Info(fmt.Sprintf("[%s] Skipped %d unmonitored %s and %d unmonitored low score %s(s)",
+			instanceName, unmonitoredParents, kind.parents, unmonitoredSkipped, kind.item))
+	} else if unmonitoredSkipped > 0 {
+		logger.Info(fmt.Sprintf("[%s] Skipped %d unmonitored low score %s(s)", instanceName, unmonitoredSkipped, kind.item))
+	}
+	if cooldownSkipped > 0 {
+		logger.Info(fmt.Sprintf("[%s] Skipped %d %s(s) searched within the last %v", instanceName, cooldownSkipped, kind.item, instance.Cooldown))
+	}
+
+	lowScoreItems = orderItems(lowScoreItems, instance.Order, svc.sortKeys())
+	if cfg.BatchSize > 0 && (reachedLimit || len(lowScoreItems) > cfg.BatchSize) {
+		logger.Info(fmt.Sprintf("[%s] Reached batch limit of %d %s", instanceName, cfg.BatchSize, kind.items))
+		lowScoreItems = lowScoreItems[:min(cfg.BatchSize, len(lowScoreItems))]
+	}
+
+	// Collect item IDs for search if enabled
+	var itemsToSearch []int
+	if cfg.TriggerSearch {
+		for _, it := range lowScoreItems {
+			itemsToSearch = append(itemsToSearch, svc.describeItem(it).id)
+		}
+	}
 
-	// Trigger searches if enabled and we have movies to search
-	if cfg.TriggerSearch && len(moviesToSearch) > 0 {
-		slog.Info(fmt.Sprintf("[%s] Triggering search for %d movie(s) with low scores...", instanceName, len(moviesToSearch)))
+	// Trigger searches if enabled and we have items to search
+	if cfg.TriggerSearch && len(itemsToSearch) > 0 {
+		logger.Info(fmt.Sprintf("[%s] Triggering search for %d %s(s) with low scores...", instanceName, len(itemsToSearch), kind.item))
 
 		// Search in batches to avoid overwhelming the system
 		batchSize := constants.DefaultSearchBatchSize
-		for i := 0; i < len(moviesToSearch); i += batchSize {
-			end := min(i+batchSize, len(moviesToSearch))
+		for i := 0; i < len(itemsToSearch) && ctx.Err() == nil; i += batchSize {
+			end := min(i+batchSize, len(itemsToSearch))
 
-			batch := moviesToSearch[i:end]
-			resp, err := client.TriggerMovieSearch(batch)
+			batch := itemsToSearch[i:end]
+			resp, err := svc.search(ctx, batch)
 			if err != nil {
-				slog.Error(fmt.Sprintf("[%s] Warning: failed to trigger search for movies %v: %v", instanceName, batch, err))
+				logger.Error(fmt.Sprintf("[%s] Warning: failed to trigger search for %s %v: %v", instanceName, kind.items, batch, err))
 				continue
 			}
+			if instance.Cooldown > 0 {
+				store.RecordSearch(key, batch, time.Now())
+			}
 
-			slog.Info(fmt.Sprintf("[%s] Search triggered for batch: %v (Command ID: %d, Status: %s)",
+			logger.Info(fmt.Sprintf("[%s] Search triggered for batch: %v (Command ID: %d, Status: %s)",
 				instanceName, batch, resp.ID, resp.Status))
 		}
 	}
 
-	return lowScoreMovies, nil
-}
-
-// printLowScoreEpisodes prints episodes with low custom format scores to console
-func printLowScoreEpisodes(episodes []types.LowScoreEpisode, triggerSearch bool, instanceName string) {
-	if len(episodes) == 0 {
-		slog.Info(fmt.Sprintf("[%s] No episodes found with custom format scores below zero.", instanceName))
-		return
+	// Entries of an interrupted run are checked again next time
+	if err := ctx.Err(); err != nil {
+		return lowScoreItems, err
 	}
 
-	slog.Info(fmt.Sprintf("[%s] Found %d episode(s) with custom format scores below zero:", instanceName, len(episodes)))
-	if triggerSearch {
-		slog.Info(fmt.Sprintf("[%s] (Searches have been triggered for these episodes)", instanceName))
-	} else {
-		slog.Info(fmt.Sprintf("[%s] (Set SCORECHECK_TRIGGER_SEARCH=true to automatically trigger searches)", instanceName))
+	// The entry the limit was hit in isn't checked until the items left in
+	// it have been looked at, which the next run does first. Once the run
+	// wraps around, only the entries checked since count towards the new pass.
+	if stopEarly {
+		if stoppedIn == partialID {
+			foundInEntry = append(foundInEntry, slices.Collect(maps.Keys(found))...)
+		}
+		store.SetPartial(key, stoppedIn, foundInEntry)
 	}
-
-	for _, ep := range episodes {
-		slog.Debug(fmt.Sprintf("[%s] Series: %s", instanceName, ep.Series.Title))
-		slog.Debug(fmt.Sprintf("[%s]   Episode: S%02dE%02d - %s", instanceName,
-			ep.Episode.SeasonNumber,
-			ep.Episode.EpisodeNumber,
-			ep.Episode.Title))
-		slog.Debug(fmt.Sprintf("[%s]   Custom Format Score: %d", instanceName, ep.CustomFormatScore))
-		slog.Debug(fmt.Sprintf("[%s]   Episode ID: %d", instanceName, ep.Episode.ID))
+	isStoppedIn := func(id int) bool { return id == stoppedIn }
+	processed = slices.DeleteFunc(processed, isStoppedIn)
+	wrapped = slices.DeleteFunc(wrapped, isStoppedIn)
+	switch {
+	case len(wrapped) > 0:
+		store.SetChecked(key, wrapped)
+	case len(processed) > 0 && stopEarly:
+		store.SetChecked(key, append(slices.Collect(maps.Keys(checked)), processed...))
 	}
+
+	return lowScoreItems, nil
 }
 
-// printLowScoreMovies prints movies with low custom format scores to console
-func printLowScoreMovies(movies []types.LowScoreMovie, triggerSearch bool, instanceName string) {
-	if len(movies) == 0 {
-		slog.Info(fmt.Sprintf("[%s] No movies found with custom format scores below zero.", instanceName))
+// printLowScore prints items with low custom format scores to console
+func printLowScore[P, T any](svc service[P, T], items []T, triggerSearch bool, instance types.ServiceConfig, logger *slog.Logger) {
+	instanceName := instance.Name
+	kind := svc.kind()
+
+	if len(items) == 0 {
+		logger.Info(fmt.Sprintf("[%s] No %s found with custom format scores %s.", instanceName, kind.items, describeLimit(instance)))
 		return
 	}
 
-	slog.Info(fmt.Sprintf("[%s] Found %d movie(s) with custom format scores below zero:", instanceName, len(movies)))
+	logger.Info(fmt.Sprintf("[%s] Found %d %s(s) with custom format scores %s:", instanceName, len(items), kind.item, describeLimit(instance)))
 	if triggerSearch {
-		slog.Info(fmt.Sprintf("[%s] (Searches have been triggered for these movies)", instanceName))
+		logger.Info(fmt.Sprintf("[%s] (Searches have been triggered for these %s)", instanceName, kind.items))
 	} else {
-		slog.Info(fmt.Sprintf("[%s] (Set SCORECHECK_TRIGGER_SEARCH=true to automatically trigger searches)", instanceName))
+		logger.Info(fmt.Sprintf("[%s] (Set SCORECHECK_TRIGGER_SEARCH=true to automatically trigger searches)", instanceName))
 	}
 
-	for _, movie := range movies {
-		slog.Debug(fmt.Sprintf("[%s] Movie: %s (%d)", instanceName, movie.Movie.Title, movie.Movie.Year))
-		slog.Debug(fmt.Sprintf("[%s]   Custom Format Score: %d", instanceName, movie.CustomFormatScore))
-		slog.Debug(fmt.Sprintf("[%s]   Movie ID: %d", instanceName, movie.Movie.ID))
+	for _, it := range items {
+		item := svc.describeItem(it)
+		logger.Debug(fmt.Sprintf("[%s] %s", instanceName, item.label))
+		logger.Debug(fmt.Sprintf("[%s]   Custom Format Score: %d", instanceName, item.score))
+		logger.Debug(fmt.Sprintf("[%s]   ID: %d", instanceName, item.id))
 	}
 }
 
-// RunOnce runs the score checker once
-func RunOnce() {
-	cfg := config.Load()
+// parentSkip records whether a library entry is skipped before its items are fetched
+type parentSkip struct {
+	message     string // why the entry is skipped, "" if it's checked
+	unmonitored bool
+}
 
-	if cfg.TriggerSearch {
-		slog.Info("Search triggering is ENABLED - will automatically search for better versions")
-	} else {
-		slog.Info("Search triggering is DISABLED - will only report findings")
+// skipParent applies the exclude list, tag filter and monitored check to a library entry
+func skipParent(kind serviceKind, instance types.ServiceConfig, tags tagFilter, parent parentInfo) parentSkip {
+	if reason := exclusionReason(instance.Exclude, parent.target); reason != "" {
+		return parentSkip{message: fmt.Sprintf("Skipping excluded %s %s: %s", kind.parent, parent.label, reason)}
 	}
-	slog.Info(fmt.Sprintf("Batch size: %d items per run", cfg.BatchSize))
-	slog.Debug(fmt.Sprintf("Log level: %s", cfg.LogLevel))
+	if reason := tags.reason(parent.tags); reason != "" {
+		return parentSkip{message: fmt.Sprintf("Skipping %s %s: %s", kind.parent, parent.label, reason)}
+	}
+	if instance.MonitoredOnly && !parent.monitored {
+		return parentSkip{
+			message:     fmt.Sprintf("Skipping unmonitored %s: %s (ID: %d)", kind.parent, parent.label, parent.target.ID),
+			unmonitored: true,
+		}
+	}
+	return parentSkip{}
+}
 
-	// Process each Sonarr instance
-	if len(cfg.SonarrInstances) > 0 {
-		slog.Info(fmt.Sprintf("Found %d Sonarr instance(s)", len(cfg.SonarrInstances)))
-		for _, instance := range cfg.SonarrInstances {
-			slog.Info(fmt.Sprintf("=== Checking Sonarr Instance: %s ===", instance.Name))
+// instanceChecks returns a check for every configured instance of a service
+func instanceChecks[P, T any](cfg types.Config, store *state.Store, instances []types.ServiceConfig, newService func(types.ServiceConfig) service[P, T]) []instanceCheck {
+	checks := make([]instanceCheck, 0, len(instances))
+	for i, instance := range instances {
+		checks = append(checks, func(ctx context.Context, logger *slog.Logger) {
+			svc := newService(instance)
+			kind := svc.kind()
+			if i == 0 {
+				logger.Info(fmt.Sprintf("Found %d %s instance(s)", len(instances), kind.name))
+			}
 
-			client := sonarr.NewClient(instance)
-			slog.Info(fmt.Sprintf("[%s] Fetching series and checking custom format scores...", instance.Name))
+			logger.Info(fmt.Sprintf("=== Checking %s Instance: %s ===", kind.name, instance.Name))
+			logger.Info(fmt.Sprintf("[%s] Fetching %s and checking custom format scores...", instance.Name, kind.parents))
 
-			lowScoreEpisodes, err := findLowScoreEpisodes(client, cfg, instance.Name)
+			items, err := findLowScore(ctx, svc, cfg, instance, store, logger)
+			if ctx.Err() != nil {
+				logger.Info(fmt.Sprintf("[%s] Check interrupted by shutdown", instance.Name))
+				return
+			}
 			if err != nil {
-				slog.Error(fmt.Sprintf("[%s] Error finding low score episodes: %v", instance.Name, err))
-				continue
+				logger.Error(fmt.Sprintf("[%s] Error finding low score %s: %v", instance.Name, kind.items, err))
+				return
 			}
 
-			printLowScoreEpisodes(lowScoreEpisodes, cfg.TriggerSearch, instance.Name)
-		}
+			printLowScore(svc, items, cfg.TriggerSearch, instance, logger)
+		})
 	}
+	return checks
+}
 
-	// Process each Radarr instance
-	if len(cfg.RadarrInstances) > 0 {
-		slog.Info(fmt.Sprintf("Found %d Radarr instance(s)", len(cfg.RadarrInstances)))
-		for _, instance := range cfg.RadarrInstances {
-			slog.Info(fmt.Sprintf("=== Checking Radarr Instance: %s ===", instance.Name))
-
-			client := radarr.NewClient(instance)
-			slog.Info(fmt.Sprintf("[%s] Fetching movies and checking custom format scores...", instance.Name))
-
-			lowScoreMovies, err := findLowScoreMovies(client, cfg, instance.Name)
-			if err != nil {
-				slog.Error(fmt.Sprintf("[%s] Error finding low score movies: %v", instance.Name, err))
-				continue
-			}
+// RunOnce runs the score checker once. Once ctx is canceled no more
+// instances are checked and the state is saved with the progress made so far.
+func RunOnce(ctx context.Context) {
+	runOnce(ctx, config.Load())
+}
 
-			printLowScoreMovies(lowScoreMovies, cfg.TriggerSearch, instance.Name)
-		}
+// runOnce checks every instance of cfg once
+func runOnce(ctx context.Context, cfg types.Config) {
+	if cfg.TriggerSearch {
+		slog.Info("Search triggering is ENABLED - will automatically search for better versions")
+	} else {
+		slog.Info("Search triggering is DISABLED - will only report findings")
 	}
+	slog.Info(fmt.Sprintf("Batch size: %d items per run", cfg.BatchSize))
+	slog.Info(fmt.Sprintf("Default score threshold: %d (mode: %s)", cfg.Threshold, cfg.Mode))
+	slog.Debug(fmt.Sprintf("Default order: %s", cfg.Order))
+	slog.Debug(fmt.Sprintf("Log level: %s", cfg.LogLevel))
 
-	if len(cfg.SonarrInstances) == 0 && len(cfg.RadarrInstances) == 0 {
-		slog.Info("No Sonarr or Radarr instances configured. Please check your configuration.")
+	store, err := state.Load(cfg.StateFile)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Warning: failed to load state, starting from scratch: %v", err))
+	}
+	defer func() {
+		if err := store.Save(); err != nil {
+			slog.Error(fmt.Sprintf("Warning: failed to save state: %v", err))
+		}
+	}()
+
+	// Instances are checked concurrently, with the output grouped per instance
+	var checks []instanceCheck
+	checks = append(checks, instanceChecks(cfg, store, cfg.SonarrInstances, func(instance types.ServiceConfig) service[types.Series, types.LowScoreEpisode] {
+		return sonarrService{sonarr.NewClient(instance)}
+	})...)
+	checks = append(checks, instanceChecks(cfg, store, cfg.RadarrInstances, func(instance types.ServiceConfig) service[types.MovieWithFile, types.LowScoreMovie] {
+		return radarrService{radarr.NewClient(instance)}
+	})...)
+	checks = append(checks, instanceChecks(cfg, store, cfg.LidarrInstances, func(instance types.ServiceConfig) service[types.Artist, types.LowScoreAlbum] {
+		return lidarrService{lidarr.NewClient(instance)}
+	})...)
+	checks = append(checks, instanceChecks(cfg, store, cfg.ReadarrInstances, func(instance types.ServiceConfig) service[types.Author, types.LowScoreBook] {
+		return readarrService{readarr.NewClient(instance)}
+	})...)
+	runChecks(ctx, checks, cfg.Concurrency)
+
+	if len(cfg.SonarrInstances) == 0 && len(cfg.RadarrInstances) == 0 && len(cfg.LidarrInstances) == 0 && len(cfg.ReadarrInstances) == 0 {
+		slog.Info("No Sonarr, Radarr, Lidarr or Readarr instances configured. Please check your configuration.")
 	}
 }
 
-// RunDaemon runs the score checker as a daemon
-func RunDaemon() {
+// RunDaemon runs the score checker as a daemon until ctx is canceled. The
+// configuration is reloaded when the config file changes or on SIGHUP, and
+// takes effect from the next run.
+func RunDaemon(ctx context.Context) {
 	cfg := config.Load()
 	slog.Info(fmt.Sprintf("Starting daemon mode with interval: %v", cfg.Interval))
 
+	reloads := config.Watch(ctx)
 	ticker := time.NewTicker(cfg.Interval)
 	defer ticker.Stop()
 
 	// Run once immediately
-	RunOnce()
+	runOnce(ctx, cfg)
 
 	// Then run on schedule
-	for range ticker.C {
-		slog.Info(fmt.Sprintf("=== Scheduled run at %s ===", time.Now().Format("2006-01-02 15:04:05")))
-		RunOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Daemon stopped")
+			return
+		case newCfg := <-reloads:
+			cfg = applyReload(cfg, newCfg, ticker)
+		case <-ticker.C:
+			// A reload that arrived at the same time goes first
+			select {
+			case newCfg := <-reloads:
+				cfg = applyReload(cfg, newCfg, ticker)
+			default:
+			}
+			slog.Info(fmt.Sprintf("=== Scheduled run at %s ===", time.Now().Format("2006-01-02 15:04:05")))
+			runOnce(ctx, cfg)
+		}
+	}
+}
+
+// applyReload switches the daemon to a reloaded configuration, restarting
+// the ticker if the interval changed
+func applyReload(cfg, newCfg types.Config, ticker *time.Ticker) types.Config {
+	if newCfg.Interval != cfg.Interval {
+		ticker.Reset(newCfg.Interval)
+		slog.Info(fmt.Sprintf("Interval changed from %v to %v", cfg.Interval, newCfg.Interval))
 	}
+	slog.Info("Configuration reloaded, it applies from the next run")
+	return newCfg
 }
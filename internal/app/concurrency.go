@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"sync"
+
+	"score-checker/internal/arr"
+)
+
+// bufferHandler holds on to an instance's log records while other instances
+// are still writing theirs, so the output of each instance stays in one piece
+type bufferHandler struct {
+	next    slog.Handler
+	mu      sync.Mutex
+	records []slog.Record
+	live    bool
+}
+
+func (h *bufferHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *bufferHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.live {
+		return h.next.Handle(ctx, r)
+	}
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *bufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *bufferHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+// flush writes the buffered records and passes any later ones straight through
+func (h *bufferHandler) flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, r := range h.records {
+		_ = h.next.Handle(context.Background(), r)
+	}
+	h.records = nil
+	h.live = true
+}
+
+// instanceCheck checks one configured instance, logging through logger. It
+// should wind down once ctx is canceled.
+type instanceCheck func(ctx context.Context, logger *slog.Logger)
+
+// runChecks runs up to concurrency checks at once, starting them in order.
+// Each check's output is written in the order the checks were given: the
+// earliest unfinished check logs live, the others are buffered until it's
+// their turn. Checks that haven't started when ctx is canceled are skipped.
+func runChecks(ctx context.Context, checks []instanceCheck, concurrency int) {
+	sem := make(chan struct{}, max(concurrency, 1))
+	handlers := make([]*bufferHandler, len(checks))
+	done := make([]chan struct{}, len(checks))
+	for i := range checks {
+		handlers[i] = &bufferHandler{next: slog.Default().Handler()}
+		done[i] = make(chan struct{})
+	}
+
+	go func() {
+		for i, check := range checks {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				close(done[i])
+				continue
+			}
+			go func() {
+				defer func() {
+					<-sem
+					close(done[i])
+				}()
+				check(ctx, slog.New(handlers[i]))
+			}()
+		}
+	}()
+
+	for i := range checks {
+		handlers[i].flush()
+		<-done[i]
+	}
+}
+
+// lookahead reads library entries from a stream and hands them to the caller
+// in order, while the items of up to workers upcoming entries are already
+// being fetched. Reading the stream and the fetches underway stop as soon as
+// the caller stops.
+type lookahead[P, R any] struct {
+	entries  chan lookaheadEntry[P, R]
+	slots    chan struct{}
+	done     chan struct{}
+	finished chan struct{}
+	once     sync.Once
+	cancel   context.CancelCauseFunc
+}
+
+// lookaheadEntry is a library entry read from the stream. result is nil for
+// entries that aren't fetched and err is set if reading the stream failed.
+type lookaheadEntry[P, R any] struct {
+	parent P
+	result chan R
+	err    error
+}
+
+// startLookahead starts reading seq, fetching the entries for which wanted
+// returns true. Fetches get a context derived from ctx, which stop cancels so
+// that requests underway are abandoned. Call next until it returns false, or
+// stop to finish early.
+func startLookahead[P, R any](ctx context.Context, seq iter.Seq2[P, error], workers int, wanted func(P) bool, fetch func(context.Context, P) R) *lookahead[P, R] {
+	workers = max(workers, 1)
+	fetchCtx, cancel := context.WithCancelCause(ctx)
+	l := &lookahead[P, R]{
+		entries:  make(chan lookaheadEntry[P, R], workers),
+		slots:    make(chan struct{}, workers),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+		cancel:   cancel,
+	}
+
+	go func() {
+		defer close(l.finished)
+		defer close(l.entries)
+
+		for parent, err := range seq {
+			entry := lookaheadEntry[P, R]{parent: parent, err: err}
+			if err == nil && wanted(parent) {
+				select {
+				case l.slots <- struct{}{}:
+				case <-l.done:
+					return
+				}
+				entry.result = make(chan R, 1)
+				go func() {
+					entry.result <- fetch(fetchCtx, parent)
+				}()
+			}
+
+			select {
+			case l.entries <- entry:
+			case <-l.done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return l
+}
+
+// next returns the next entry, or false once the stream is exhausted
+func (l *lookahead[P, R]) next() (lookaheadEntry[P, R], bool) {
+	entry, ok := <-l.entries
+	return entry, ok
+}
+
+// get waits for the fetch result of an entry returned by next
+func (l *lookahead[P, R]) get(entry lookaheadEntry[P, R]) R {
+	result := <-entry.result
+	<-l.slots
+	return result
+}
+
+// stop cancels the fetches underway, stops reading the stream and waits
+// until it is closed
+func (l *lookahead[P, R]) stop() {
+	l.once.Do(func() {
+		l.cancel(arr.ErrNotNeeded)
+		close(l.done)
+	})
+	<-l.finished
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"score-checker/internal/arr"
+)
+
+func TestRunChecksGroupsOutput(t *testing.T) {
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	// Later checks finish first, their output must still come after the earlier ones
+	delays := []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 0}
+	var checks []instanceCheck
+	for i, delay := range delays {
+		checks = append(checks, func(ctx context.Context, logger *slog.Logger) {
+			logger.Info(fmt.Sprintf("check %d start", i))
+			time.Sleep(delay)
+			logger.Info(fmt.Sprintf("check %d end", i))
+		})
+	}
+
+	runChecks(context.Background(), checks, len(checks))
+
+	output := buf.String()
+	last := -1
+	for i := range delays {
+		for _, event := range []string{"start", "end"} {
+			index := strings.Index(output, fmt.Sprintf("check %d %s", i, event))
+			if index < 0 {
+				t.Fatalf("expected output to contain check %d %s, got: %s", i, event, output)
+			}
+			if index < last {
+				t.Fatalf("expected grouped output in check order, got: %s", output)
+			}
+			last = index
+		}
+	}
+}
+
+func TestRunChecksConcurrencyLimit(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	var running, peak atomic.Int32
+	var checks []instanceCheck
+	for range 6 {
+		checks = append(checks, func(ctx context.Context, logger *slog.Logger) {
+			current := running.Add(1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		})
+	}
+
+	runChecks(context.Background(), checks, 2)
+
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 checks at once, got %d", peak.Load())
+	}
+}
+
+func TestRunChecksCanceled(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	// The first check is interrupted by the shutdown, the rest never start
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started atomic.Int32
+	var checks []instanceCheck
+	for range 3 {
+		checks = append(checks, func(ctx context.Context, logger *slog.Logger) {
+			started.Add(1)
+			cancel()
+			<-ctx.Done()
+		})
+	}
+
+	runChecks(ctx, checks, 1)
+
+	if n := started.Load(); n != 1 {
+		t.Errorf("expected only the first check to run, %d started", n)
+	}
+}
+
+func TestLookahead(t *testing.T) {
+	var read, fetched atomic.Int32
+	closed := make(chan struct{})
+	seq := func(yield func(int, error) bool) {
+		defer close(closed)
+		for i := range 20 {
+			read.Add(1)
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+
+	// Odd entries are skipped, the earlier entries are fetched slowest to
+	// shuffle completion order
+	ahead := startLookahead(context.Background(), seq, 2, func(i int) bool { return i%2 == 0 }, func(_ context.Context, i int) int {
+		fetched.Add(1)
+		time.Sleep(time.Duration(20-i) * time.Millisecond / 4)
+		return i * 10
+	})
+
+	for expected := range 5 {
+		entry, ok := ahead.next()
+		if !ok {
+			t.Fatal("expected more entries")
+		}
+		if entry.parent != expected {
+			t.Fatalf("expected entry %d, got %d", expected, entry.parent)
+		}
+		if expected%2 == 1 {
+			if entry.result != nil {
+				t.Errorf("expected entry %d not to be fetched", expected)
+			}
+			continue
+		}
+		if got := ahead.get(entry); got != expected*10 {
+			t.Errorf("expected result %d for entry %d, got %d", expected*10, expected, got)
+		}
+	}
+	ahead.stop()
+
+	select {
+	case <-closed:
+	default:
+		t.Fatal("expected the stream to be closed once stopped")
+	}
+	if n := read.Load(); n >= 20 {
+		t.Errorf("expected reading to stop early, read %d entries", n)
+	}
+	if n := fetched.Load(); n > 5 {
+		t.Errorf("expected at most 5 fetches after stopping early, got %d", n)
+	}
+}
+
+func TestLookaheadStopCancelsFetches(t *testing.T) {
+	seq := func(yield func(int, error) bool) {
+		for i := range 5 {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+
+	// Fetches only finish once their context is canceled
+	started := make(chan struct{}, 5)
+	causes := make(chan error, 5)
+	ahead := startLookahead(context.Background(), seq, 2, func(int) bool { return true }, func(ctx context.Context, i int) int {
+		started <- struct{}{}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return i
+	})
+
+	if _, ok := ahead.next(); !ok {
+		t.Fatal("expected an entry")
+	}
+	<-started
+	ahead.stop()
+
+	select {
+	case cause := <-causes:
+		if !errors.Is(cause, arr.ErrNotNeeded) {
+			t.Errorf("expected the fetch to be canceled as not needed, got %v", cause)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the fetch underway to be canceled once stopped")
+	}
+}
+
+func TestLookaheadError(t *testing.T) {
+	seq := func(yield func(int, error) bool) {
+		if yield(1, nil) {
+			yield(0, errors.New("connection reset"))
+		}
+	}
+	ahead := startLookahead(context.Background(), seq, 1, func(int) bool { return false }, func(_ context.Context, i int) int { return i })
+	defer ahead.stop()
+
+	if entry, ok := ahead.next(); !ok || entry.err != nil || entry.parent != 1 {
+		t.Fatalf("expected entry 1, got %+v (ok: %v)", entry, ok)
+	}
+	if entry, ok := ahead.next(); !ok || entry.err == nil {
+		t.Fatalf("expected stream error, got %+v (ok: %v)", entry, ok)
+	}
+	if _, ok := ahead.next(); ok {
+		t.Error("expected no entries after the error")
+	}
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"score-checker/internal/arr"
+	"score-checker/internal/config"
+	"score-checker/internal/lidarr"
+	"score-checker/internal/radarr"
+	"score-checker/internal/readarr"
+	"score-checker/internal/sonarr"
+	"score-checker/internal/types"
+)
+
+// Outcomes of probing an instance, besides an unexpected HTTP status
+const (
+	connectionOK          = "OK"
+	connectionUnreachable = "unreachable"
+	connectionAuthFailed  = "auth failed"
+	connectionBadResponse = "invalid response"
+	connectionNotChecked  = "not checked"
+)
+
+// probeTarget is an instance to probe, along with its service's name
+type probeTarget struct {
+	service string
+	client  *arr.Client
+}
+
+// connectionResult is the outcome of probing one instance
+type connectionResult struct {
+	service  string
+	instance string
+	version  string
+	status   string
+	latency  time.Duration
+	health   []string // problems reported by the instance's health checks
+	err      error
+}
+
+// probe checks that an instance is reachable and accepts its API key, then
+// collects the problems its health checks report. The latency is that of
+// the system status request.
+func probe(ctx context.Context, target probeTarget) connectionResult {
+	result := connectionResult{service: target.service, instance: target.client.Config().Name}
+
+	start := time.Now()
+	status, err := target.client.GetSystemStatus(ctx)
+	result.latency = time.Since(start)
+	if err != nil {
+		result.status = connectionStatus(err)
+		result.err = err
+		return result
+	}
+	result.status = connectionOK
+	result.version = status.Version
+
+	// Health problems are reported, but don't fail the check
+	checks, err := target.client.GetHealth(ctx)
+	if err != nil {
+		result.health = []string{fmt.Sprintf("health checks unavailable: %v", err)}
+		return result
+	}
+	for _, check := range checks {
+		if check.Type == "ok" {
+			continue
+		}
+		result.health = append(result.health, fmt.Sprintf("%s: %s", check.Type, check.Message))
+	}
+	return result
+}
+
+// connectionStatus describes why a system status request failed
+func connectionStatus(err error) string {
+	var statusErr *arr.StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden {
+			return connectionAuthFailed
+		}
+		return fmt.Sprintf("HTTP %d", statusErr.StatusCode)
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return connectionUnreachable
+	}
+	return connectionBadResponse
+}
+
+// probeTargets returns a probe target for every configured instance
+func probeTargets(cfg types.Config) []probeTarget {
+	var targets []probeTarget
+	for _, instance := range cfg.SonarrInstances {
+		targets = append(targets, probeTarget{"Sonarr", sonarr.NewClient(instance).Client})
+	}
+	for _, instance := range cfg.RadarrInstances {
+		targets = append(targets, probeTarget{"Radarr", radarr.NewClient(instance).Client})
+	}
+	for _, instance := range cfg.LidarrInstances {
+		targets = append(targets, probeTarget{"Lidarr", lidarr.NewClient(instance).Client})
+	}
+	for _, instance := range cfg.ReadarrInstances {
+		targets = append(targets, probeTarget{"Readarr", readarr.NewClient(instance).Client})
+	}
+	return targets
+}
+
+// probeAll probes up to concurrency instances at once, returning the results
+// in the order of targets. Instances not yet probed when ctx is canceled are
+// reported as not checked.
+func probeAll(ctx context.Context, targets []probeTarget, concurrency int) []connectionResult {
+	results := make([]connectionResult, len(targets))
+	checks := make([]instanceCheck, len(targets))
+	for i, target := range targets {
+		results[i] = connectionResult{service: target.service, instance: target.client.Config().Name, status: connectionNotChecked}
+		checks[i] = func(ctx context.Context, _ *slog.Logger) {
+			results[i] = probe(ctx, target)
+		}
+	}
+	runChecks(ctx, checks, concurrency)
+	return results
+}
+
+// writeConnectionTable writes the probe results to w as a table, followed by
+// the errors of the instances that failed. It returns the number of failures.
+func writeConnectionTable(w io.Writer, results []connectionResult) (int, error) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tINSTANCE\tVERSION\tSTATUS\tLATENCY\tHEALTH")
+
+	failed := 0
+	for _, result := range results {
+		if result.status != connectionOK {
+			failed++
+		}
+
+		version, latency, health := "-", "-", "-"
+		if result.version != "" {
+			version = result.version
+		}
+		if result.status != connectionNotChecked {
+			latency = result.latency.Round(time.Millisecond).String()
+		}
+		if result.status == connectionOK {
+			health = "OK"
+			if len(result.health) > 0 {
+				health = result.health[0]
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", result.service, result.instance, version, result.status, latency, health)
+
+		// Further health problems go on lines of their own
+		for i := 1; i < len(result.health); i++ {
+			fmt.Fprintf(tw, "\t\t\t\t\t%s\n", result.health[i])
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return failed, err
+	}
+
+	if failed > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+	}
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(w, "  [%s] %s instance: %v\n", result.instance, result.service, result.err)
+		}
+	}
+	return failed, nil
+}
+
+// CheckConnections probes every configured instance and writes a table of
+// each one's version, status, latency and health problems to w. It returns an
+// error if any instance can't be reached or doesn't accept its API key.
+func CheckConnections(ctx context.Context, w io.Writer) error {
+	cfg := config.Load()
+
+	targets := probeTargets(cfg)
+	if len(targets) == 0 {
+		return errors.New("no Sonarr, Radarr, Lidarr or Readarr instances configured")
+	}
+
+	results := probeAll(ctx, targets, cfg.Concurrency)
+	failed, err := writeConnectionTable(w, results)
+	if err != nil {
+		return fmt.Errorf("writing results: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instance(s) failed the connection check", failed, len(results))
+	}
+	return nil
+}
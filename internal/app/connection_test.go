@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"score-checker/internal/arr"
+	"score-checker/internal/config"
+	"score-checker/internal/testhelpers"
+	"score-checker/internal/types"
+)
+
+func TestProbe(t *testing.T) {
+	health := []types.HealthCheck{
+		{Source: "IndexerStatusCheck", Type: "warning", Message: "Indexers unavailable due to failures"},
+		{Source: "UpdateCheck", Type: "ok", Message: ""},
+	}
+	server := testhelpers.MockStatusServer(t, arr.APIv3, "test-api-key", types.SystemStatus{AppName: "Sonarr", Version: "4.0.9"}, health)
+	defer server.Close()
+
+	closed := testhelpers.MockStatusServer(t, arr.APIv3, "test-api-key", types.SystemStatus{}, nil)
+	closed.Close()
+
+	tests := []struct {
+		name            string
+		baseURL         string
+		apiKey          string
+		apiBase         string
+		expectedStatus  string
+		expectedVersion string
+		expectedHealth  []string
+	}{
+		{
+			name:            "reachable",
+			baseURL:         server.URL,
+			apiKey:          "test-api-key",
+			apiBase:         arr.APIv3,
+			expectedStatus:  connectionOK,
+			expectedVersion: "4.0.9",
+			expectedHealth:  []string{"warning: Indexers unavailable due to failures"},
+		},
+		{
+			name:           "wrong API key",
+			baseURL:        server.URL,
+			apiKey:         "wrong-key",
+			apiBase:        arr.APIv3,
+			expectedStatus: connectionAuthFailed,
+		},
+		{
+			name:           "wrong API version",
+			baseURL:        server.URL,
+			apiKey:         "test-api-key",
+			apiBase:        arr.APIv1,
+			expectedStatus: "HTTP 404",
+		},
+		{
+			name:           "unreachable",
+			baseURL:        closed.URL,
+			apiKey:         "test-api-key",
+			apiBase:        arr.APIv3,
+			expectedStatus: connectionUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := arr.NewClient(types.ServiceConfig{Name: "main", BaseURL: tt.baseURL, APIKey: tt.apiKey}, tt.apiBase)
+			result := probe(context.Background(), probeTarget{service: "Sonarr", client: client})
+
+			if result.service != "Sonarr" || result.instance != "main" {
+				t.Errorf("expected Sonarr instance 'main', got %s instance '%s'", result.service, result.instance)
+			}
+			if result.status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q (error: %v)", tt.expectedStatus, result.status, result.err)
+			}
+			if result.version != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, result.version)
+			}
+			if strings.Join(result.health, "|") != strings.Join(tt.expectedHealth, "|") {
+				t.Errorf("expected health %q, got %q", tt.expectedHealth, result.health)
+			}
+			if (result.err == nil) != (tt.expectedStatus == connectionOK) {
+				t.Errorf("expected an error only for failed probes, got %v", result.err)
+			}
+		})
+	}
+}
+
+func TestProbeAllCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := arr.NewClient(types.ServiceConfig{Name: "main", BaseURL: "http://localhost:1"}, arr.APIv3)
+	results := probeAll(ctx, []probeTarget{{service: "Sonarr", client: client}}, 1)
+
+	if len(results) != 1 || results[0].status != connectionNotChecked || results[0].instance != "main" {
+		t.Errorf("expected the instance to be reported as not checked, got %+v", results)
+	}
+}
+
+func TestWriteConnectionTable(t *testing.T) {
+	results := []connectionResult{
+		{
+			service:  "Sonarr",
+			instance: "main",
+			version:  "4.0.9",
+			status:   connectionOK,
+			latency:  12 * time.Millisecond,
+			health:   []string{"warning: Indexers unavailable", "error: Download client unavailable"},
+		},
+		{service: "Radarr", instance: "4k", version: "5.8.3", status: connectionOK, latency: 8 * time.Millisecond},
+		{
+			service:  "Lidarr",
+			instance: "music",
+			status:   connectionAuthFailed,
+			latency:  3 * time.Millisecond,
+			err:      &arr.StatusError{StatusCode: 401},
+		},
+		{service: "Readarr", instance: "books", status: connectionNotChecked},
+	}
+
+	var buf bytes.Buffer
+	failed, err := writeConnectionTable(&buf, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failed != 2 {
+		t.Errorf("expected 2 failures, got %d", failed)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	expectedLines := []string{
+		"SERVICE  INSTANCE  VERSION  STATUS       LATENCY  HEALTH",
+		"Sonarr   main      4.0.9    OK           12ms     warning: Indexers unavailable",
+		"                                                  error: Download client unavailable",
+		"Radarr   4k        5.8.3    OK           8ms      OK",
+		"Lidarr   music     -        auth failed  3ms      -",
+		"Readarr  books     -        not checked  -        -",
+		"",
+		"Errors:",
+		"  [music] Lidarr instance: API request failed with status 401",
+	}
+	for i, expected := range expectedLines {
+		if i >= len(lines) || lines[i] != expected {
+			t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(expectedLines, "\n"), buf.String())
+			break
+		}
+	}
+}
+
+func TestCheckConnections(t *testing.T) {
+	sonarrServer := testhelpers.MockStatusServer(t, arr.APIv3, "test-sonarr-key", types.SystemStatus{AppName: "Sonarr", Version: "4.0.9"}, nil)
+	defer sonarrServer.Close()
+	readarrServer := testhelpers.MockStatusServer(t, arr.APIv1, "test-readarr-key", types.SystemStatus{AppName: "Readarr", Version: "0.4.1"}, nil)
+	defer readarrServer.Close()
+
+	// Run in a temporary directory so the log file isn't written into the package
+	t.Chdir(t.TempDir())
+
+	// Reset viper for clean test, and again afterwards so the instances of
+	// the closed mock servers don't leak into other tests
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	config.Init()
+	viper.Set("sonarr", []map[string]interface{}{
+		{"name": "main", "baseurl": sonarrServer.URL, "apikey": "test-sonarr-key"},
+	})
+	viper.Set("readarr", []map[string]interface{}{
+		{"name": "books", "baseurl": readarrServer.URL, "apikey": "test-readarr-key"},
+	})
+
+	var buf bytes.Buffer
+	if err := CheckConnections(context.Background(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, buf.String())
+	}
+	for _, expected := range []string{"4.0.9", "0.4.1"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+
+	// A rejected API key fails the check
+	viper.Set("readarr", []map[string]interface{}{
+		{"name": "books", "baseurl": readarrServer.URL, "apikey": "wrong-key"},
+	})
+	buf.Reset()
+	if err := CheckConnections(context.Background(), &buf); err == nil {
+		t.Errorf("expected error for a rejected API key, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), connectionAuthFailed) {
+		t.Errorf("expected output to contain %q, got:\n%s", connectionAuthFailed, buf.String())
+	}
+}
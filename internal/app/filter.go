@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"score-checker/internal/types"
+)
+
+// filterTarget holds the fields of a series or movie that filters match on
+type filterTarget struct {
+	ID     int
+	Title  string
+	TvdbID int
+	TmdbID int
+	ImdbID string
+}
+
+// seriesTarget describes a Sonarr series for the filters
+func seriesTarget(s types.Series) filterTarget {
+	return filterTarget{ID: s.ID, Title: s.Title, TvdbID: s.TvdbID, TmdbID: s.TmdbID, ImdbID: s.ImdbID}
+}
+
+// movieTarget describes a Radarr movie for the filters
+func movieTarget(m types.MovieWithFile) filterTarget {
+	return filterTarget{ID: m.ID, Title: m.Title, TmdbID: m.TmdbID, ImdbID: m.ImdbID}
+}
+
+// artistTarget describes a Lidarr artist for the filters
+func artistTarget(a types.Artist) filterTarget {
+	return filterTarget{ID: a.ID, Title: a.ArtistName}
+}
+
+// authorTarget describes a Readarr author for the filters
+func authorTarget(a types.Author) filterTarget {
+	return filterTarget{ID: a.ID, Title: a.AuthorName}
+}
+
+// exclusionReason returns why target matches the exclude list, or "" if it doesn't
+func exclusionReason(exclude types.ExcludeConfig, target filterTarget) string {
+	for _, title := range exclude.Titles {
+		if strings.EqualFold(title, target.Title) {
+			return fmt.Sprintf("title matches %q", title)
+		}
+	}
+	for _, pattern := range exclude.Patterns {
+		if pattern.MatchString(target.Title) {
+			return fmt.Sprintf("title matches pattern %q", pattern.String())
+		}
+	}
+	if slices.Contains(exclude.IDs, target.ID) {
+		return fmt.Sprintf("ID %d is excluded", target.ID)
+	}
+	if target.TvdbID != 0 && slices.Contains(exclude.TvdbIDs, target.TvdbID) {
+		return fmt.Sprintf("TVDB ID %d is excluded", target.TvdbID)
+	}
+	if target.TmdbID != 0 && slices.Contains(exclude.TmdbIDs, target.TmdbID) {
+		return fmt.Sprintf("TMDB ID %d is excluded", target.TmdbID)
+	}
+	if target.ImdbID != "" && slices.ContainsFunc(exclude.ImdbIDs, func(id string) bool {
+		return strings.EqualFold(id, target.ImdbID)
+	}) {
+		return fmt.Sprintf("IMDb ID %s is excluded", target.ImdbID)
+	}
+	return ""
+}
+
+// tagFilter limits items by their Sonarr/Radarr tags
+type tagFilter struct {
+	include        map[int]string // tag ID to label
+	exclude        map[int]string
+	requireInclude bool // include_tags was configured, even if none of the labels exist
+}
+
+// loadTagFilter resolves the instance's include_tags and exclude_tags labels
+// to tag IDs. Tags are only fetched when either list is configured.
+func loadTagFilter(instance types.ServiceConfig, fetch func() ([]types.Tag, error), logger *slog.Logger) (tagFilter, error) {
+	filter := tagFilter{requireInclude: len(instance.IncludeTags) > 0}
+	if len(instance.IncludeTags) == 0 && len(instance.ExcludeTags) == 0 {
+		return filter, nil
+	}
+
+	tags, err := fetch()
+	if err != nil {
+		return filter, fmt.Errorf("getting tags: %w", err)
+	}
+
+	resolve := func(labels []string) map[int]string {
+		resolved := make(map[int]string)
+		for _, label := range labels {
+			index := slices.IndexFunc(tags, func(tag types.Tag) bool {
+				return strings.EqualFold(tag.Label, label)
+			})
+			if index < 0 {
+				logger.Error(fmt.Sprintf("[%s] Warning: tag %q does not exist", instance.Name, label))
+				continue
+			}
+			resolved[tags[index].ID] = tags[index].Label
+		}
+		return resolved
+	}
+
+	filter.include = resolve(instance.IncludeTags)
+	filter.exclude = resolve(instance.ExcludeTags)
+	return filter, nil
+}
+
+// reason returns why an item with the given tags is filtered out, or "" if it isn't
+func (f tagFilter) reason(tags []int) string {
+	for _, id := range tags {
+		if label, ok := f.exclude[id]; ok {
+			return fmt.Sprintf("tagged %q", label)
+		}
+	}
+	if !f.requireInclude {
+		return ""
+	}
+	for _, id := range tags {
+		if _, ok := f.include[id]; ok {
+			return ""
+		}
+	}
+	return "not tagged with any of include_tags"
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"score-checker/internal/radarr"
+	"score-checker/internal/sonarr"
+	"score-checker/internal/testhelpers"
+	"score-checker/internal/types"
+)
+
+func TestExclusionReason(t *testing.T) {
+	exclude := types.ExcludeConfig{
+		Titles:   []string{"One Piece"},
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)^planet earth`)},
+		IDs:      []int{42},
+		TvdbIDs:  []int{81189},
+		TmdbIDs:  []int{603},
+		ImdbIDs:  []string{"tt0133093"},
+	}
+
+	tests := []struct {
+		name     string
+		target   filterTarget
+		excluded bool
+	}{
+		{name: "exact title ignores case", target: filterTarget{ID: 1, Title: "one piece"}, excluded: true},
+		{name: "title pattern", target: filterTarget{ID: 2, Title: "Planet Earth II"}, excluded: true},
+		{name: "internal ID", target: filterTarget{ID: 42, Title: "Some Show"}, excluded: true},
+		{name: "TVDB ID", target: filterTarget{ID: 3, Title: "Breaking Bad", TvdbID: 81189}, excluded: true},
+		{name: "TMDB ID", target: filterTarget{ID: 4, Title: "The Matrix", TmdbID: 603}, excluded: true},
+		{name: "IMDb ID", target: filterTarget{ID: 5, Title: "The Matrix", ImdbID: "TT0133093"}, excluded: true},
+		{name: "partial title is not an exact match", target: filterTarget{ID: 6, Title: "One Piece Film: Red"}, excluded: false},
+		{name: "unknown external IDs never match", target: filterTarget{ID: 7, Title: "Inception"}, excluded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := exclusionReason(exclude, tt.target)
+			if (reason != "") != tt.excluded {
+				t.Errorf("expected excluded=%v, got reason %q", tt.excluded, reason)
+			}
+		})
+	}
+}
+
+func TestFindLowScoreEpisodesExclude(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	server := testhelpers.MockSonarrServer(t, testhelpers.CreateTestSeries(), testhelpers.CreateTestEpisodes(), nil)
+	defer server.Close()
+
+	instance := types.ServiceConfig{
+		Name:    "test",
+		BaseURL: server.URL,
+		APIKey:  "test-api-key",
+		Exclude: types.ExcludeConfig{Titles: []string{"Breaking Bad"}},
+	}
+
+	found, err := findLowScore(context.Background(), sonarrService{sonarr.NewClient(instance)}, types.Config{}, instance, nil, slog.Default())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Series.Title != "Better Call Saul" {
+		t.Errorf("expected only the Better Call Saul episode, got %+v", found)
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	fetch := func() ([]types.Tag, error) { return testhelpers.CreateTestTags(), nil }
+
+	tests := []struct {
+		name        string
+		includeTags []string
+		excludeTags []string
+		tags        []int
+		filtered    bool
+	}{
+		{name: "no tag settings", tags: []int{1}, filtered: false},
+		{name: "include matches ignoring case", includeTags: []string{"Anime"}, tags: []int{1}, filtered: false},
+		{name: "include without match", includeTags: []string{"anime"}, tags: []int{2}, filtered: true},
+		{name: "include untagged item", includeTags: []string{"anime"}, tags: nil, filtered: true},
+		{name: "exclude match", excludeTags: []string{"keep"}, tags: []int{1, 2}, filtered: true},
+		{name: "exclude wins over include", includeTags: []string{"anime"}, excludeTags: []string{"keep"}, tags: []int{1, 2}, filtered: true},
+		{name: "unknown include label matches nothing", includeTags: []string{"missing"}, tags: []int{1}, filtered: true},
+		{name: "unknown exclude label is ignored", excludeTags: []string{"missing"}, tags: []int{1}, filtered: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := types.ServiceConfig{Name: "test", IncludeTags: tt.includeTags, ExcludeTags: tt.excludeTags}
+			filter, err := loadTagFilter(instance, fetch, slog.Default())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reason := filter.reason(tt.tags); (reason != "") != tt.filtered {
+				t.Errorf("expected filtered=%v, got reason %q", tt.filtered, reason)
+			}
+		})
+	}
+}
+
+func TestFindLowScoreMoviesTags(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	movies := testhelpers.CreateTestMovies()
+	movies[0].Tags = []int{2}
+	movies = append(movies, types.MovieWithFile{
+		ID:        4,
+		Title:     "Tenet",
+		Year:      2020,
+		HasFile:   true,
+		MovieFile: &types.MovieFile{ID: 104, CustomFormatScore: -20},
+	})
+	server := testhelpers.MockRadarrServer(t, movies, nil)
+	defer server.Close()
+
+	instance := types.ServiceConfig{
+		Name:        "test",
+		BaseURL:     server.URL,
+		APIKey:      "test-api-key",
+		ExcludeTags: []string{"keep"},
+	}
+
+	found, err := findLowScore(context.Background(), radarrService{radarr.NewClient(instance)}, types.Config{}, instance, nil, slog.Default())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Movie.ID != 4 {
+		t.Errorf("expected only the untagged movie (4), got %+v", found)
+	}
+}
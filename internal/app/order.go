@@ -0,0 +1,171 @@
+package app
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"score-checker/internal/constants"
+	"score-checker/internal/types"
+)
+
+// sortKeys extracts the values the order strategies sort by
+type sortKeys[T any] struct {
+	score    func(T) int
+	added    func(T) time.Time // when the file was imported
+	released func(T) time.Time // when the episode aired or the movie was released
+	group    func(T) int       // items sharing a group are interleaved by round-robin
+}
+
+// episodeSortKeys sorts episodes, grouping them by series for round-robin
+var episodeSortKeys = sortKeys[types.LowScoreEpisode]{
+	score: func(e types.LowScoreEpisode) int { return e.CustomFormatScore },
+	added: func(e types.LowScoreEpisode) time.Time {
+		if e.Episode.EpisodeFile == nil {
+			return time.Time{}
+		}
+		return e.Episode.EpisodeFile.DateAdded
+	},
+	released: func(e types.LowScoreEpisode) time.Time { return e.Episode.AirDateUTC },
+	group:    func(e types.LowScoreEpisode) int { return e.Series.ID },
+}
+
+// movieSortKeys sorts movies. Every movie is its own group, so round-robin
+// keeps the library order.
+var movieSortKeys = sortKeys[types.LowScoreMovie]{
+	score: func(m types.LowScoreMovie) int { return m.CustomFormatScore },
+	added: func(m types.LowScoreMovie) time.Time {
+		if m.Movie.MovieFile == nil {
+			return time.Time{}
+		}
+		return m.Movie.MovieFile.DateAdded
+	},
+	released: func(m types.LowScoreMovie) time.Time { return movieReleaseDate(m.Movie) },
+	group:    func(m types.LowScoreMovie) int { return m.Movie.ID },
+}
+
+// albumSortKeys sorts albums, grouping them by artist for round-robin
+var albumSortKeys = sortKeys[types.LowScoreAlbum]{
+	score: func(a types.LowScoreAlbum) int { return a.CustomFormatScore },
+	added: func(a types.LowScoreAlbum) time.Time {
+		var added time.Time
+		for _, trackFile := range a.TrackFiles {
+			if added.IsZero() || (!trackFile.DateAdded.IsZero() && trackFile.DateAdded.Before(added)) {
+				added = trackFile.DateAdded
+			}
+		}
+		return added
+	},
+	released: func(a types.LowScoreAlbum) time.Time { return a.Album.ReleaseDate },
+	group:    func(a types.LowScoreAlbum) int { return a.Artist.ID },
+}
+
+// bookSortKeys sorts books, grouping them by author for round-robin
+var bookSortKeys = sortKeys[types.LowScoreBook]{
+	score: func(b types.LowScoreBook) int { return b.CustomFormatScore },
+	added: func(b types.LowScoreBook) time.Time {
+		var added time.Time
+		for _, bookFile := range b.BookFiles {
+			if added.IsZero() || (!bookFile.DateAdded.IsZero() && bookFile.DateAdded.Before(added)) {
+				added = bookFile.DateAdded
+			}
+		}
+		return added
+	},
+	released: func(b types.LowScoreBook) time.Time { return b.Book.ReleaseDate },
+	group:    func(b types.LowScoreBook) int { return b.Author.ID },
+}
+
+// movieReleaseDate returns the earliest home release of a movie, falling
+// back to its cinema release
+func movieReleaseDate(movie types.MovieWithFile) time.Time {
+	var released time.Time
+	for _, date := range []time.Time{movie.DigitalRelease, movie.PhysicalRelease} {
+		if !date.IsZero() && (released.IsZero() || date.Before(released)) {
+			released = date
+		}
+	}
+	if released.IsZero() {
+		return movie.InCinemas
+	}
+	return released
+}
+
+// sortsCandidates reports whether an order strategy needs every candidate
+// before the batch limit can be applied
+func sortsCandidates(order string) bool {
+	return order != "" && order != constants.OrderDefault
+}
+
+// orderItems returns items arranged according to an order strategy.
+// Ties keep their library order.
+func orderItems[T any](items []T, order string, keys sortKeys[T]) []T {
+	ordered := slices.Clone(items)
+
+	switch order {
+	case constants.OrderLowestScore:
+		slices.SortStableFunc(ordered, func(a, b T) int {
+			return cmp.Compare(keys.score(a), keys.score(b))
+		})
+	case constants.OrderOldestFile:
+		slices.SortStableFunc(ordered, func(a, b T) int {
+			return compareTimes(keys.added(a), keys.added(b))
+		})
+	case constants.OrderNewest:
+		slices.SortStableFunc(ordered, func(a, b T) int {
+			releasedA, releasedB := keys.released(a), keys.released(b)
+			if releasedA.IsZero() || releasedB.IsZero() {
+				return compareTimes(releasedA, releasedB)
+			}
+			return releasedB.Compare(releasedA)
+		})
+	case constants.OrderRandom:
+		rand.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	case constants.OrderRoundRobin:
+		ordered = roundRobin(ordered, keys.group)
+	}
+
+	return ordered
+}
+
+// compareTimes orders times ascending, with unknown (zero) times last
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+	return a.Compare(b)
+}
+
+// roundRobin interleaves items so each group contributes one item per round.
+// Groups take turns in the order they first appear.
+func roundRobin[T any](items []T, group func(T) int) []T {
+	var groupOrder []int
+	groups := make(map[int][]T)
+	for _, item := range items {
+		g := group(item)
+		if _, ok := groups[g]; !ok {
+			groupOrder = append(groupOrder, g)
+		}
+		groups[g] = append(groups[g], item)
+	}
+
+	result := make([]T, 0, len(items))
+	for len(result) < len(items) {
+		for _, g := range groupOrder {
+			if len(groups[g]) > 0 {
+				result = append(result, groups[g][0])
+				groups[g] = groups[g][1:]
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"score-checker/internal/constants"
+	"score-checker/internal/radarr"
+	"score-checker/internal/testhelpers"
+	"score-checker/internal/types"
+)
+
+func testOrderEpisodes() []types.LowScoreEpisode {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	episode := func(seriesID, id, score int, added, aired time.Time) types.LowScoreEpisode {
+		return types.LowScoreEpisode{
+			Series: types.Series{ID: seriesID},
+			Episode: types.Episode{
+				ID:          id,
+				AirDateUTC:  aired,
+				EpisodeFile: &types.EpisodeFile{CustomFormatScore: score, DateAdded: added},
+			},
+			CustomFormatScore: score,
+		}
+	}
+
+	return []types.LowScoreEpisode{
+		episode(1, 11, -5, day(3), day(1)),
+		episode(1, 12, -50, day(2), day(9)),
+		episode(1, 13, -1, time.Time{}, day(4)),
+		episode(2, 21, -20, day(1), time.Time{}),
+		episode(3, 31, -5, day(5), day(6)),
+	}
+}
+
+func episodeIDs(episodes []types.LowScoreEpisode) []int {
+	ids := make([]int, len(episodes))
+	for i, ep := range episodes {
+		ids[i] = ep.Episode.ID
+	}
+	return ids
+}
+
+func TestOrderItems(t *testing.T) {
+	tests := []struct {
+		order    string
+		expected []int
+	}{
+		{order: "", expected: []int{11, 12, 13, 21, 31}},
+		{order: constants.OrderDefault, expected: []int{11, 12, 13, 21, 31}},
+		{order: constants.OrderLowestScore, expected: []int{12, 21, 11, 31, 13}},
+		{order: constants.OrderOldestFile, expected: []int{21, 12, 11, 31, 13}},
+		{order: constants.OrderNewest, expected: []int{12, 31, 13, 11, 21}},
+		{order: constants.OrderRoundRobin, expected: []int{11, 21, 31, 12, 13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			input := testOrderEpisodes()
+			got := episodeIDs(orderItems(input, tt.order, episodeSortKeys))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+
+			// The input must be left untouched
+			if ids := episodeIDs(input); ids[0] != 11 || ids[4] != 31 {
+				t.Errorf("expected input order to be preserved, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestOrderItemsRandom(t *testing.T) {
+	input := testOrderEpisodes()
+	got := orderItems(input, constants.OrderRandom, episodeSortKeys)
+
+	if len(got) != len(input) {
+		t.Fatalf("expected %d items, got %d", len(input), len(got))
+	}
+	seen := make(map[int]bool)
+	for _, ep := range got {
+		seen[ep.Episode.ID] = true
+	}
+	for _, ep := range input {
+		if !seen[ep.Episode.ID] {
+			t.Errorf("expected episode %d to be kept", ep.Episode.ID)
+		}
+	}
+}
+
+func TestMovieReleaseDate(t *testing.T) {
+	cinema := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	physical := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	digital := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		movie    types.MovieWithFile
+		expected time.Time
+	}{
+		{name: "earliest home release", movie: types.MovieWithFile{InCinemas: cinema, PhysicalRelease: physical, DigitalRelease: digital}, expected: digital},
+		{name: "physical only", movie: types.MovieWithFile{InCinemas: cinema, PhysicalRelease: physical}, expected: physical},
+		{name: "cinema only", movie: types.MovieWithFile{InCinemas: cinema}, expected: cinema},
+		{name: "unknown", movie: types.MovieWithFile{}, expected: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := movieReleaseDate(tt.movie); !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindLowScoreMoviesLowestScoreFirst(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	movies := append(testhelpers.CreateTestMovies(), types.MovieWithFile{
+		ID:        4,
+		Title:     "Tenet",
+		Year:      2020,
+		HasFile:   true,
+		MovieFile: &types.MovieFile{ID: 104, CustomFormatScore: -20},
+	})
+	server := testhelpers.MockRadarrServer(t, movies, nil)
+	defer server.Close()
+
+	instance := types.ServiceConfig{
+		Name:    "test",
+		BaseURL: server.URL,
+		APIKey:  "test-api-key",
+		Order:   constants.OrderLowestScore,
+	}
+
+	found, err := findLowScore(context.Background(), radarrService{radarr.NewClient(instance)}, types.Config{BatchSize: 1}, instance, nil, slog.Default())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Movie.ID != 4 {
+		t.Errorf("expected only the worst scoring movie (4), got %+v", found)
+	}
+}
@@ -0,0 +1,349 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"score-checker/internal/lidarr"
+	"score-checker/internal/radarr"
+	"score-checker/internal/readarr"
+	"score-checker/internal/sonarr"
+	"score-checker/internal/types"
+)
+
+// serviceKind names a service and what it works with in logs and state keys
+type serviceKind struct {
+	name    string // e.g. "Sonarr"
+	key     string // state key prefix, e.g. "sonarr"
+	parent  string // what the library lists, e.g. "series"
+	parents string
+	item    string // what gets searched for, e.g. "episode"
+	items   string
+}
+
+// parentInfo is what the pipeline needs to know about a library entry
+type parentInfo struct {
+	target           filterTarget
+	label            string // human readable description for logs
+	tags             []int
+	monitored        bool
+	qualityProfileID int
+}
+
+// itemInfo is what the pipeline needs to know about a low score item
+type itemInfo struct {
+	id        int
+	label     string // human readable description for logs
+	score     int
+	monitored bool
+}
+
+// service adapts an *arr application to the low score pipeline. P is what
+// the library lists (series, movies, artists, authors) and T is a low score
+// item that can be searched for (episodes, movies, albums, books).
+type service[P, T any] interface {
+	GetQualityProfiles(ctx context.Context) ([]types.QualityProfile, error)
+	GetTags(ctx context.Context) ([]types.Tag, error)
+
+	kind() serviceKind
+	// all streams the whole library
+	all(ctx context.Context) iter.Seq2[P, error]
+	describeParent(parent P) parentInfo
+	// lowScoreItems returns the items of parent with files scoring below limit
+	lowScoreItems(ctx context.Context, parent P, limit int) ([]T, error)
+	describeItem(item T) itemInfo
+	// search triggers a search for better versions of the given items
+	search(ctx context.Context, ids []int) (*types.CommandResponse, error)
+	sortKeys() sortKeys[T]
+}
+
+// sonarrService checks the episodes of every Sonarr series
+type sonarrService struct {
+	*sonarr.Client
+}
+
+func (sonarrService) kind() serviceKind {
+	return serviceKind{name: "Sonarr", key: "sonarr", parent: "series", parents: "series", item: "episode", items: "episodes"}
+}
+
+func (s sonarrService) all(ctx context.Context) iter.Seq2[types.Series, error] {
+	return s.StreamSeries(ctx)
+}
+
+func (sonarrService) describeParent(series types.Series) parentInfo {
+	return parentInfo{
+		target:           seriesTarget(series),
+		label:            series.Title,
+		tags:             series.Tags,
+		monitored:        series.Monitored,
+		qualityProfileID: series.QualityProfileID,
+	}
+}
+
+func (s sonarrService) lowScoreItems(ctx context.Context, series types.Series, limit int) ([]types.LowScoreEpisode, error) {
+	// Series without any files have nothing to score
+	if series.Statistics != nil && series.Statistics.EpisodeFileCount == 0 {
+		return nil, nil
+	}
+
+	episodeFiles, err := s.GetEpisodeFiles(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	lowFiles := make(map[int]types.EpisodeFile)
+	for _, episodeFile := range episodeFiles {
+		if episodeFile.CustomFormatScore < limit {
+			lowFiles[episodeFile.ID] = episodeFile
+		}
+	}
+	if len(lowFiles) == 0 {
+		return nil, nil
+	}
+
+	// Only now look up which episodes the low scoring files belong to
+	episodes, err := s.GetEpisodes(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lowScore []types.LowScoreEpisode
+	for _, episode := range episodes {
+		episodeFile, ok := lowFiles[episode.EpisodeFileID]
+		if !episode.HasFile || !ok {
+			continue
+		}
+		episode.EpisodeFile = &episodeFile
+		lowScore = append(lowScore, types.LowScoreEpisode{
+			Series:            series,
+			Episode:           episode,
+			CustomFormatScore: episodeFile.CustomFormatScore,
+		})
+	}
+	return lowScore, nil
+}
+
+func (sonarrService) describeItem(ep types.LowScoreEpisode) itemInfo {
+	return itemInfo{
+		id: ep.Episode.ID,
+		label: fmt.Sprintf("%s S%02dE%02d - %s", ep.Series.Title,
+			ep.Episode.SeasonNumber, ep.Episode.EpisodeNumber, ep.Episode.Title),
+		score:     ep.CustomFormatScore,
+		monitored: episodeMonitored(ep.Series, ep.Episode),
+	}
+}
+
+func (s sonarrService) search(ctx context.Context, ids []int) (*types.CommandResponse, error) {
+	return s.TriggerEpisodeSearch(ctx, ids)
+}
+
+func (sonarrService) sortKeys() sortKeys[types.LowScoreEpisode] { return episodeSortKeys }
+
+// radarrService checks every Radarr movie. Movies are both the library
+// entries and the items searched for.
+type radarrService struct {
+	*radarr.Client
+}
+
+func (radarrService) kind() serviceKind {
+	return serviceKind{name: "Radarr", key: "radarr", parent: "movie", parents: "movies", item: "movie", items: "movies"}
+}
+
+func (r radarrService) all(ctx context.Context) iter.Seq2[types.MovieWithFile, error] {
+	return r.StreamMovies(ctx)
+}
+
+func (radarrService) describeParent(movie types.MovieWithFile) parentInfo {
+	// Unmonitored movies are reported as unmonitored items instead
+	return parentInfo{
+		target:           movieTarget(movie),
+		label:            fmt.Sprintf("%s (%d)", movie.Title, movie.Year),
+		tags:             movie.Tags,
+		monitored:        true,
+		qualityProfileID: movie.QualityProfileID,
+	}
+}
+
+func (radarrService) lowScoreItems(ctx context.Context, movie types.MovieWithFile, limit int) ([]types.LowScoreMovie, error) {
+	if !movie.HasFile || movie.MovieFile == nil || movie.MovieFile.CustomFormatScore >= limit {
+		return nil, nil
+	}
+	return []types.LowScoreMovie{{Movie: movie, CustomFormatScore: movie.MovieFile.CustomFormatScore}}, nil
+}
+
+func (radarrService) describeItem(movie types.LowScoreMovie) itemInfo {
+	return itemInfo{
+		id:        movie.Movie.ID,
+		label:     fmt.Sprintf("%s (%d)", movie.Movie.Title, movie.Movie.Year),
+		score:     movie.CustomFormatScore,
+		monitored: movie.Movie.Monitored,
+	}
+}
+
+func (r radarrService) search(ctx context.Context, ids []int) (*types.CommandResponse, error) {
+	return r.TriggerMovieSearch(ctx, ids)
+}
+
+func (radarrService) sortKeys() sortKeys[types.LowScoreMovie] { return movieSortKeys }
+
+// lidarrService checks the track files of every Lidarr artist and searches
+// for whole albums
+type lidarrService struct {
+	*lidarr.Client
+}
+
+func (lidarrService) kind() serviceKind {
+	return serviceKind{name: "Lidarr", key: "lidarr", parent: "artist", parents: "artists", item: "album", items: "albums"}
+}
+
+func (l lidarrService) all(ctx context.Context) iter.Seq2[types.Artist, error] {
+	return l.StreamArtists(ctx)
+}
+
+func (lidarrService) describeParent(artist types.Artist) parentInfo {
+	return parentInfo{
+		target:           artistTarget(artist),
+		label:            artist.ArtistName,
+		tags:             artist.Tags,
+		monitored:        artist.Monitored,
+		qualityProfileID: artist.QualityProfileID,
+	}
+}
+
+func (l lidarrService) lowScoreItems(ctx context.Context, artist types.Artist, limit int) ([]types.LowScoreAlbum, error) {
+	trackFiles, err := l.GetTrackFiles(ctx, artist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the low scoring track files by album
+	lowTracks := make(map[int][]types.TrackFile)
+	for _, trackFile := range trackFiles {
+		if trackFile.CustomFormatScore < limit {
+			lowTracks[trackFile.AlbumID] = append(lowTracks[trackFile.AlbumID], trackFile)
+		}
+	}
+	if len(lowTracks) == 0 {
+		return nil, nil
+	}
+
+	albums, err := l.GetAlbums(ctx, artist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lowScore []types.LowScoreAlbum
+	for _, album := range albums {
+		tracks, ok := lowTracks[album.ID]
+		if !ok {
+			continue
+		}
+		lowest := slices.MinFunc(tracks, func(a, b types.TrackFile) int {
+			return cmp.Compare(a.CustomFormatScore, b.CustomFormatScore)
+		})
+		lowScore = append(lowScore, types.LowScoreAlbum{
+			Artist:            artist,
+			Album:             album,
+			CustomFormatScore: lowest.CustomFormatScore,
+			TrackFiles:        tracks,
+		})
+	}
+	return lowScore, nil
+}
+
+func (lidarrService) describeItem(album types.LowScoreAlbum) itemInfo {
+	return itemInfo{
+		id:        album.Album.ID,
+		label:     fmt.Sprintf("%s - %s (%d low scoring track(s))", album.Artist.ArtistName, album.Album.Title, len(album.TrackFiles)),
+		score:     album.CustomFormatScore,
+		monitored: album.Album.Monitored,
+	}
+}
+
+func (l lidarrService) search(ctx context.Context, ids []int) (*types.CommandResponse, error) {
+	return l.TriggerAlbumSearch(ctx, ids)
+}
+
+func (lidarrService) sortKeys() sortKeys[types.LowScoreAlbum] { return albumSortKeys }
+
+// readarrService checks the book files of every Readarr author
+type readarrService struct {
+	*readarr.Client
+}
+
+func (readarrService) kind() serviceKind {
+	return serviceKind{name: "Readarr", key: "readarr", parent: "author", parents: "authors", item: "book", items: "books"}
+}
+
+func (r readarrService) all(ctx context.Context) iter.Seq2[types.Author, error] {
+	return r.StreamAuthors(ctx)
+}
+
+func (readarrService) describeParent(author types.Author) parentInfo {
+	return parentInfo{
+		target:           authorTarget(author),
+		label:            author.AuthorName,
+		tags:             author.Tags,
+		monitored:        author.Monitored,
+		qualityProfileID: author.QualityProfileID,
+	}
+}
+
+func (r readarrService) lowScoreItems(ctx context.Context, author types.Author, limit int) ([]types.LowScoreBook, error) {
+	bookFiles, err := r.GetBookFiles(ctx, author.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the low scoring book files by book
+	lowFiles := make(map[int][]types.BookFile)
+	for _, bookFile := range bookFiles {
+		if bookFile.CustomFormatScore < limit {
+			lowFiles[bookFile.BookID] = append(lowFiles[bookFile.BookID], bookFile)
+		}
+	}
+	if len(lowFiles) == 0 {
+		return nil, nil
+	}
+
+	books, err := r.GetBooks(ctx, author.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lowScore []types.LowScoreBook
+	for _, book := range books {
+		files, ok := lowFiles[book.ID]
+		if !ok {
+			continue
+		}
+		lowest := slices.MinFunc(files, func(a, b types.BookFile) int {
+			return cmp.Compare(a.CustomFormatScore, b.CustomFormatScore)
+		})
+		lowScore = append(lowScore, types.LowScoreBook{
+			Author:            author,
+			Book:              book,
+			CustomFormatScore: lowest.CustomFormatScore,
+			BookFiles:         files,
+		})
+	}
+	return lowScore, nil
+}
+
+func (readarrService) describeItem(book types.LowScoreBook) itemInfo {
+	return itemInfo{
+		id:        book.Book.ID,
+		label:     fmt.Sprintf("%s - %s (%d low scoring file(s))", book.Author.AuthorName, book.Book.Title, len(book.BookFiles)),
+		score:     book.CustomFormatScore,
+		monitored: book.Book.Monitored,
+	}
+}
+
+func (r readarrService) search(ctx context.Context, ids []int) (*types.CommandResponse, error) {
+	return r.TriggerBookSearch(ctx, ids)
+}
+
+func (readarrService) sortKeys() sortKeys[types.LowScoreBook] { return bookSortKeys }
@@ -0,0 +1,396 @@
+package arr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"score-checker/internal/types"
+)
+
+// API bases used by the *arr applications
+const (
+	// APIv3 is used by Sonarr and Radarr
+	APIv3 = "/api/v3"
+	// APIv1 is used by Lidarr and Readarr
+	APIv1 = "/api/v1"
+)
+
+// ErrNotNeeded is the cause to cancel a request's context with when its
+// response is no longer needed, so it's abandoned without a grace period
+var ErrNotNeeded = errors.New("response no longer needed")
+
+// StatusError is returned when an instance answers a GET request with a
+// status other than 200 OK
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+}
+
+// Client handles the API interactions shared by every *arr application.
+// Service specific clients embed it and add their own endpoints.
+type Client struct {
+	config  types.ServiceConfig
+	apiBase string
+	client  *http.Client
+	// stream makes the requests whose response is read as it's consumed,
+	// which the whole request timeout of client would cut short
+	stream  *http.Client
+	limiter *limiter
+}
+
+// NewClient creates a new API client for an instance serving its API under apiBase
+func NewClient(config types.ServiceConfig, apiBase string) *Client {
+	transport := newTransport(config.Transport)
+	return &Client{
+		config:  config,
+		apiBase: apiBase,
+		client: &http.Client{
+			Timeout:   config.Transport.Timeout,
+			Transport: transport,
+		},
+		stream:  &http.Client{Transport: transport},
+		limiter: newLimiter(config.RateLimit),
+	}
+}
+
+// newTransport returns the default transport with the instance's TLS and
+// proxy settings applied
+func newTransport(config types.TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.TLS != nil {
+		transport.TLSClientConfig = config.TLS.Clone()
+	}
+	if config.Proxy != nil {
+		transport.Proxy = http.ProxyURL(config.Proxy)
+	}
+	transport.ResponseHeaderTimeout = config.Timeout
+	return transport
+}
+
+// Config returns the instance configuration the client was created with
+func (c *Client) Config() types.ServiceConfig {
+	return c.config
+}
+
+// requestContext returns the context for a single request. Once ctx is
+// canceled no new requests are started, but one that is already underway
+// gets the instance's grace period to finish before it's abandoned, unless
+// ctx was canceled with ErrNotNeeded.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	reqCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		if errors.Is(context.Cause(ctx), ErrNotNeeded) {
+			cancel()
+			return
+		}
+		time.AfterFunc(c.config.GracePeriod, cancel)
+	})
+	return reqCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// cancelOnClose releases a request's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// readTimeout abandons a streamed response once a single read of its body
+// takes longer than timeout. A stalled server is noticed that way, while the
+// time the caller spends between reads doesn't count.
+type readTimeout struct {
+	io.ReadCloser
+	timeout time.Duration
+	cancel  context.CancelFunc
+	expired atomic.Bool
+}
+
+func (b *readTimeout) Read(p []byte) (int, error) {
+	timer := time.AfterFunc(b.timeout, func() {
+		b.expired.Store(true)
+		b.cancel()
+	})
+	n, err := b.ReadCloser.Read(p)
+	timer.Stop()
+	if err != nil && b.expired.Load() {
+		err = fmt.Errorf("no response data received within %v: %w", b.timeout, err)
+	}
+	return n, err
+}
+
+// authenticate adds the instance's extra headers, basic auth credentials and
+// API key to a request. The API key goes in the X-Api-Key header, or in the
+// apikey query parameter for proxies that strip custom headers.
+func (c *Client) authenticate(req *http.Request) {
+	for name, value := range c.config.Headers {
+		// Go ignores a Host header, it has to be set on the request itself
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if auth := c.config.BasicAuth; auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+
+	if c.config.APIKeyInQuery {
+		query := req.URL.Query()
+		query.Set("apikey", c.config.APIKey)
+		req.URL.RawQuery = query.Encode()
+		return
+	}
+	req.Header.Set("X-Api-Key", c.config.APIKey)
+}
+
+// redact removes the API key from the URL in a request error, so it doesn't
+// end up in the logs when it's sent as a query parameter
+func (c *Client) redact(err error) error {
+	var urlErr *url.Error
+	if c.config.APIKeyInQuery && c.config.APIKey != "" && errors.As(err, &urlErr) {
+		urlErr.URL = strings.ReplaceAll(urlErr.URL, url.QueryEscape(c.config.APIKey), "REDACTED")
+	}
+	return err
+}
+
+// open makes an authenticated GET request and returns the response body,
+// which the caller must close. The instance's timeout covers the whole
+// request, unless stream is set: the body is then read only as fast as the
+// caller consumes it, so the timeout applies to waiting for the headers and
+// to each read of the body instead.
+func (c *Client) open(ctx context.Context, endpoint string, params url.Values, stream bool) (io.ReadCloser, error) {
+	// Build URL
+	u, err := url.Parse(c.config.BaseURL + c.apiBase + endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Add query parameters
+	if params != nil {
+		u.RawQuery = params.Encode()
+	}
+
+	// Make request, GETs can always be retried
+	client := c.client
+	if stream {
+		client = c.stream
+	}
+	reqCtx, cancel := c.requestContext(ctx)
+	resp, err := c.send(ctx, client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(reqCtx, "GET", u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		c.authenticate(req)
+		return req, nil
+	}, true)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("making request: %w", c.redact(err))
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body := io.ReadCloser(cancelOnClose{ReadCloser: resp.Body, cancel: cancel})
+	if stream && c.config.Transport.Timeout > 0 {
+		body = &readTimeout{ReadCloser: body, timeout: c.config.Transport.Timeout, cancel: cancel}
+	}
+	return body, nil
+}
+
+// Get fetches an endpoint relative to the API base and decodes the
+// response into v
+func (c *Client) Get(ctx context.Context, endpoint string, params url.Values, v any) error {
+	body, err := c.open(ctx, endpoint, params, false)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("unmarshaling response: %w", err)
+	}
+
+	return nil
+}
+
+// Stream fetches an endpoint returning a JSON array and decodes its elements
+// one at a time as the response is read, so large libraries are never held
+// in memory as a whole. Breaking out of the loop stops reading the response.
+func Stream[T any](ctx context.Context, c *Client, endpoint string, params url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		body, err := c.open(ctx, endpoint, params, true)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer body.Close()
+
+		dec := json.NewDecoder(body)
+		if err := expectDelim(dec, '['); err != nil {
+			yield(zero, err)
+			return
+		}
+		for dec.More() {
+			var item T
+			if err := dec.Decode(&item); err != nil {
+				yield(zero, fmt.Errorf("unmarshaling response: %w", err))
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// expectDelim reads the next token and checks it is the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("unmarshaling response: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("unmarshaling response: expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// Collect reads every element of a stream into a slice
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetQualityProfiles fetches all quality profiles
+func (c *Client) GetQualityProfiles(ctx context.Context) ([]types.QualityProfile, error) {
+	var profiles []types.QualityProfile
+	if err := c.Get(ctx, "/qualityprofile", nil, &profiles); err != nil {
+		return nil, fmt.Errorf("fetching quality profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// GetTags fetches all tags
+func (c *Client) GetTags(ctx context.Context) ([]types.Tag, error) {
+	var tags []types.Tag
+	if err := c.Get(ctx, "/tag", nil, &tags); err != nil {
+		return nil, fmt.Errorf("fetching tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetSystemStatus fetches the application's name and version
+func (c *Client) GetSystemStatus(ctx context.Context) (*types.SystemStatus, error) {
+	var status types.SystemStatus
+	if err := c.Get(ctx, "/system/status", nil, &status); err != nil {
+		return nil, fmt.Errorf("fetching system status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// GetHealth fetches the problems the application's health checks currently report
+func (c *Client) GetHealth(ctx context.Context) ([]types.HealthCheck, error) {
+	var checks []types.HealthCheck
+	if err := c.Get(ctx, "/health", nil, &checks); err != nil {
+		return nil, fmt.Errorf("fetching health: %w", err)
+	}
+
+	return checks, nil
+}
+
+// Command posts a command such as a search and returns the queued command
+func (c *Client) Command(ctx context.Context, command any) (*types.CommandResponse, error) {
+	// Marshal to JSON
+	jsonData, err := json.Marshal(command)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling command request: %w", err)
+	}
+
+	// Build URL
+	u, err := url.Parse(c.config.BaseURL + c.apiBase + "/command")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Make POST request. Posting a command twice queues it twice, so it's
+	// only retried when the first attempt can't have been acted on.
+	reqCtx, cancel := c.requestContext(ctx)
+	defer cancel()
+	resp, err := c.send(ctx, c.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(reqCtx, "POST", u.String(), bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		// Set headers
+		c.authenticate(req)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, false)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", c.redact(err))
+	}
+	defer resp.Body.Close()
+
+	// Check status code
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	// Parse response
+	var commandResp types.CommandResponse
+	if err := json.Unmarshal(body, &commandResp); err != nil {
+		return nil, fmt.Errorf("unmarshaling command response: %w", err)
+	}
+
+	return &commandResp, nil
+}
@@ -0,0 +1,734 @@
+package arr
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"score-checker/internal/testhelpers"
+	"score-checker/internal/types"
+)
+
+func TestNewClient(t *testing.T) {
+	config := types.ServiceConfig{
+		Name:    "test",
+		BaseURL: "http://localhost:8989",
+		APIKey:  "test-api-key",
+	}
+
+	client := NewClient(config, APIv3)
+
+	if client == nil {
+		t.Fatal("expected client to not be nil")
+	}
+	if client.Config().Name != config.Name {
+		t.Errorf("expected config name %q, got %q", config.Name, client.Config().Name)
+	}
+	if client.apiBase != APIv3 {
+		t.Errorf("expected api base %q, got %q", APIv3, client.apiBase)
+	}
+	if client.client == nil {
+		t.Error("expected http client to not be nil")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name         string
+		apiBase      string
+		endpoint     string
+		responseCode int
+		responseBody string
+		expectError  bool
+		errorMessage string
+	}{
+		{
+			name:         "successful request",
+			apiBase:      APIv3,
+			endpoint:     "/series",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 1, "title": "Test Series"}]`,
+			expectError:  false,
+		},
+		{
+			name:         "successful v1 request",
+			apiBase:      APIv1,
+			endpoint:     "/artist",
+			responseCode: http.StatusOK,
+			responseBody: `[]`,
+			expectError:  false,
+		},
+		{
+			name:         "server error",
+			apiBase:      APIv3,
+			endpoint:     "/series",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+			errorMessage: "API request failed with status 500",
+		},
+		{
+			name:         "not found error",
+			apiBase:      APIv3,
+			endpoint:     "/notfound",
+			responseCode: http.StatusNotFound,
+			responseBody: `{"error": "not found"}`,
+			expectError:  true,
+			errorMessage: "API request failed with status 404",
+		},
+		{
+			name:         "unauthorized error",
+			apiBase:      APIv3,
+			endpoint:     "/series",
+			responseCode: http.StatusUnauthorized,
+			responseBody: `{"error": "unauthorized"}`,
+			expectError:  true,
+			errorMessage: "API request failed with status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.apiBase+tt.endpoint {
+					t.Errorf("expected path %q, got %q", tt.apiBase+tt.endpoint, r.URL.Path)
+				}
+				if r.Header.Get("X-Api-Key") != "test-api-key" {
+					t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
+				}
+
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			config := types.ServiceConfig{
+				Name:    "test",
+				BaseURL: server.URL,
+				APIKey:  "test-api-key",
+			}
+			client := NewClient(config, tt.apiBase)
+
+			body, err := client.open(context.Background(), tt.endpoint, nil, false)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if !strings.Contains(err.Error(), tt.errorMessage) {
+					t.Errorf("expected error message to contain %q, got %q", tt.errorMessage, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer body.Close()
+
+				data, err := io.ReadAll(body)
+				if err != nil {
+					t.Fatalf("unexpected error reading body: %v", err)
+				}
+				if string(data) != tt.responseBody {
+					t.Errorf("expected body %q, got %q", tt.responseBody, string(data))
+				}
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("seriesId") != "1" {
+			t.Errorf("expected seriesId 1, got %q", r.URL.Query().Get("seriesId"))
+		}
+		if r.URL.Path == "/api/v3/invalid" {
+			_, _ = w.Write([]byte(`invalid json`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 101, "title": "Pilot"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"}, APIv3)
+	params := url.Values{"seriesId": {"1"}}
+
+	var episodes []types.Episode
+	if err := client.Get(context.Background(), "/episode", params, &episodes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(episodes) != 1 || episodes[0].ID != 101 || episodes[0].Title != "Pilot" {
+		t.Errorf("unexpected episodes: %+v", episodes)
+	}
+
+	if err := client.Get(context.Background(), "/invalid", params, &episodes); err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestGetSystemStatusAndHealth(t *testing.T) {
+	health := []types.HealthCheck{{Source: "IndexerStatusCheck", Type: "warning", Message: "Indexers unavailable"}}
+	server := testhelpers.MockStatusServer(t, APIv1, "test-api-key", types.SystemStatus{AppName: "Lidarr", Version: "2.5.3"}, health)
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"}, APIv1)
+
+	status, err := client.GetSystemStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.AppName != "Lidarr" || status.Version != "2.5.3" {
+		t.Errorf("unexpected system status: %+v", status)
+	}
+
+	checks, err := client.GetHealth(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(checks, health) {
+		t.Errorf("expected health %+v, got %+v", health, checks)
+	}
+
+	// A wrong API key is reported with its status code
+	client = NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "wrong-key"}, APIv1)
+	_, err = client.GetSystemStatus(context.Background())
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 status error, got %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		stopAfter    int
+		expectedIDs  []int
+		expectError  bool
+	}{
+		{
+			name:         "whole array",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 1, "title": "Pilot"}, {"id": 2, "title": "Gray Matter"}]`,
+			expectedIDs:  []int{1, 2},
+		},
+		{
+			name:         "stops early",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 1}, {"id": 2}, {"id": 3}]`,
+			stopAfter:    2,
+			expectedIDs:  []int{1, 2},
+		},
+		{
+			name:         "empty array",
+			responseCode: http.StatusOK,
+			responseBody: `[]`,
+		},
+		{
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+		},
+		{
+			name:         "not an array",
+			responseCode: http.StatusOK,
+			responseBody: `{"id": 1}`,
+			expectError:  true,
+		},
+		{
+			name:         "truncated response",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 1}, {"id": 2`,
+			expectedIDs:  []int{1},
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"}, APIv3)
+
+			var ids []int
+			var streamErr error
+			for episode, err := range Stream[types.Episode](context.Background(), client, "/episode", nil) {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				ids = append(ids, episode.ID)
+				if len(ids) == tt.stopAfter {
+					break
+				}
+			}
+
+			if tt.expectError && streamErr == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && streamErr != nil {
+				t.Errorf("unexpected error: %v", streamErr)
+			}
+			if !slices.Equal(ids, tt.expectedIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	// A library large enough not to fit in the connection's buffers
+	var library []types.Episode
+	for id := 1; id <= 2000; id++ {
+		library = append(library, types.Episode{ID: id, Title: strings.Repeat("x", 1024)})
+	}
+	content, err := json.Marshal(library)
+	if err != nil {
+		t.Fatalf("failed to marshal library: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/stalled" {
+			_, _ = w.Write([]byte(`[{"id": 1},`))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	timeout := 50 * time.Millisecond
+	client := NewClient(types.ServiceConfig{
+		Name:      "test",
+		BaseURL:   server.URL,
+		APIKey:    "test-api-key",
+		Transport: types.TransportConfig{Timeout: timeout},
+	}, APIv3)
+
+	t.Run("read more slowly than the timeout", func(t *testing.T) {
+		start := time.Now()
+		count := 0
+		for _, err := range Stream[types.Episode](context.Background(), client, "/episode", nil) {
+			if err != nil {
+				t.Fatalf("unexpected error after %d episodes: %v", count, err)
+			}
+			count++
+			// Pause while most of the library is still to be read
+			if count == 1 {
+				time.Sleep(2 * timeout)
+			}
+		}
+		if count != len(library) {
+			t.Errorf("expected %d episodes, got %d", len(library), count)
+		}
+		if elapsed := time.Since(start); elapsed <= timeout {
+			t.Errorf("expected reading to take longer than the timeout, took %v", elapsed)
+		}
+	})
+
+	t.Run("stalled server", func(t *testing.T) {
+		var streamErr error
+		for _, err := range Stream[types.Episode](context.Background(), client, "/stalled", nil) {
+			if err != nil {
+				streamErr = err
+			}
+		}
+		if streamErr == nil || !strings.Contains(streamErr.Error(), "no response data received") {
+			t.Errorf("expected the stalled response to time out, got %v", streamErr)
+		}
+	})
+}
+
+func TestCollect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1}, {"id": 2}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"}, APIv3)
+
+	episodes, err := Collect(Stream[types.Episode](context.Background(), client, "/episode", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(episodes) != 2 || episodes[1].ID != 2 {
+		t.Errorf("unexpected episodes: %+v", episodes)
+	}
+
+	if _, err := Collect(Stream[types.Episode](context.Background(), client, "/missing\x00", nil)); err == nil {
+		t.Error("expected error for invalid URL")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectError  bool
+	}{
+		{
+			name:         "created",
+			responseCode: http.StatusCreated,
+			responseBody: `{"id": 123, "name": "MoviesSearch", "commandName": "MoviesSearch", "status": "queued"}`,
+		},
+		{
+			name:         "ok",
+			responseCode: http.StatusOK,
+			responseBody: `{"id": 123, "name": "MoviesSearch", "commandName": "MoviesSearch", "status": "queued"}`,
+		},
+		{
+			name:         "bad request",
+			responseCode: http.StatusBadRequest,
+			responseBody: `{"error": "bad request"}`,
+			expectError:  true,
+		},
+		{
+			name:         "invalid json",
+			responseCode: http.StatusCreated,
+			responseBody: `invalid json`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" {
+					t.Errorf("expected POST method, got %q", r.Method)
+				}
+				if r.URL.Path != "/api/v1/command" {
+					t.Errorf("expected path '/api/v1/command', got %q", r.URL.Path)
+				}
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("expected Content-Type header 'application/json', got %q", r.Header.Get("Content-Type"))
+				}
+
+				var cmdReq map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&cmdReq); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if cmdReq["name"] != "MoviesSearch" {
+					t.Errorf("expected command name 'MoviesSearch', got %v", cmdReq["name"])
+				}
+
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"}, APIv1)
+
+			resp, err := client.Command(context.Background(), map[string]interface{}{"name": "MoviesSearch", "movieIds": []int{1}})
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.ID != 123 || resp.Status != "queued" {
+				t.Errorf("unexpected command response: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		cause       error
+		expectError bool
+	}{
+		{
+			name:        "request finishes within the grace period",
+			gracePeriod: time.Second,
+			expectError: false,
+		},
+		{
+			name:        "request abandoned after the grace period",
+			gracePeriod: 10 * time.Millisecond,
+			expectError: true,
+		},
+		{
+			name:        "response no longer needed",
+			gracePeriod: time.Second,
+			cause:       ErrNotNeeded,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(100 * time.Millisecond):
+					_, _ = w.Write([]byte(`[]`))
+				case <-r.Context().Done():
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{
+				Name:        "test",
+				BaseURL:     server.URL,
+				APIKey:      "test-api-key",
+				GracePeriod: tt.gracePeriod,
+			}, APIv3)
+
+			ctx, cancel := context.WithCancelCause(context.Background())
+			go func() {
+				<-started
+				cancel(tt.cause)
+			}()
+
+			var episodes []types.Episode
+			err := client.Get(ctx, "/episode", nil, &episodes)
+			if tt.expectError && err == nil {
+				t.Error("expected the request to be abandoned")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("expected the request to finish, got: %v", err)
+			}
+
+			// No new requests are started once canceled
+			if err := client.Get(ctx, "/episode", nil, &episodes); err == nil {
+				t.Error("expected new requests to fail once canceled")
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow/api/v3/episode" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	dir := t.TempDir()
+	certFile, keyFile := testhelpers.WriteTestCertificate(t, dir)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	tests := []struct {
+		name        string
+		baseURL     string
+		transport   types.TransportConfig
+		expectError bool
+	}{
+		{
+			name:        "unknown CA",
+			baseURL:     server.URL,
+			transport:   types.TransportConfig{TLS: &tls.Config{Certificates: []tls.Certificate{cert}}},
+			expectError: true,
+		},
+		{
+			name:        "custom CA without client certificate",
+			baseURL:     server.URL,
+			transport:   types.TransportConfig{TLS: &tls.Config{RootCAs: roots}},
+			expectError: true,
+		},
+		{
+			name:      "custom CA with client certificate",
+			baseURL:   server.URL,
+			transport: types.TransportConfig{TLS: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}}},
+		},
+		{
+			name:      "skip verification",
+			baseURL:   server.URL,
+			transport: types.TransportConfig{TLS: &tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{cert}}},
+		},
+		{
+			name:    "timeout",
+			baseURL: server.URL + "/slow",
+			transport: types.TransportConfig{
+				Timeout: 20 * time.Millisecond,
+				TLS:     &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}},
+			},
+			expectError: true,
+		},
+		{
+			name:      "proxy",
+			baseURL:   "http://sonarr.internal:8989",
+			transport: types.TransportConfig{Proxy: proxyURL},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(types.ServiceConfig{
+				Name:      "test",
+				BaseURL:   tt.baseURL,
+				APIKey:    "test-api-key",
+				Transport: tt.transport,
+			}, APIv3)
+
+			var episodes []types.Episode
+			err := client.Get(context.Background(), "/episode", nil, &episodes)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	select {
+	case target := <-proxied:
+		if target != "http://sonarr.internal:8989/api/v3/episode" {
+			t.Errorf("expected the proxy to be asked for the instance URL, got %q", target)
+		}
+	default:
+		t.Error("expected the request to go through the proxy")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config types.ServiceConfig
+		check  func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:   "API key header",
+			config: types.ServiceConfig{APIKey: "test-api-key"},
+			check: func(t *testing.T, r *http.Request) {
+				if r.Header.Get("X-Api-Key") != "test-api-key" {
+					t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
+				}
+				if r.URL.Query().Has("apikey") {
+					t.Error("expected no apikey query parameter")
+				}
+			},
+		},
+		{
+			name:   "API key query parameter",
+			config: types.ServiceConfig{APIKey: "test-api-key", APIKeyInQuery: true},
+			check: func(t *testing.T, r *http.Request) {
+				if r.URL.Query().Get("apikey") != "test-api-key" {
+					t.Errorf("expected apikey query parameter 'test-api-key', got %q", r.URL.Query().Get("apikey"))
+				}
+				if r.Method == "GET" && r.URL.Query().Get("seriesId") != "1" {
+					t.Errorf("expected seriesId to be kept, got %q", r.URL.Query().Get("seriesId"))
+				}
+				if r.Header.Get("X-Api-Key") != "" {
+					t.Error("expected no X-Api-Key header")
+				}
+			},
+		},
+		{
+			name: "basic auth and headers",
+			config: types.ServiceConfig{
+				APIKey:    "test-api-key",
+				BasicAuth: &types.BasicAuth{Username: "user", Password: "secret"},
+				Headers:   map[string]string{"cf-access-client-id": "client-id", "host": "sonarr.example.com"},
+			},
+			check: func(t *testing.T, r *http.Request) {
+				if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+					t.Errorf("expected basic auth user:secret, got %q:%q (ok: %v)", user, password, ok)
+				}
+				if r.Header.Get("CF-Access-Client-Id") != "client-id" {
+					t.Errorf("expected CF-Access-Client-Id header 'client-id', got %q", r.Header.Get("CF-Access-Client-Id"))
+				}
+				if r.Host != "sonarr.example.com" {
+					t.Errorf("expected Host 'sonarr.example.com', got %q", r.Host)
+				}
+				if r.Header.Get("X-Api-Key") != "test-api-key" {
+					t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				if r.Method == "POST" {
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id": 1}`))
+					return
+				}
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			tt.config.BaseURL = server.URL
+			client := NewClient(tt.config, APIv3)
+
+			var episodes []types.Episode
+			if err := client.Get(context.Background(), "/episode", url.Values{"seriesId": {"1"}}, &episodes); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := client.Command(context.Background(), map[string]any{"name": "SeriesSearch", "seriesId": 1}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRedactQueryAPIKey(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := NewClient(types.ServiceConfig{
+		Name:          "test",
+		BaseURL:       "http://" + addr,
+		APIKey:        "secret-api-key",
+		APIKeyInQuery: true,
+	}, APIv3)
+
+	var episodes []types.Episode
+	err = client.Get(context.Background(), "/episode", nil, &episodes)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if strings.Contains(err.Error(), "secret-api-key") {
+		t.Errorf("expected the API key to be redacted, got: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package arr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"score-checker/internal/types"
+)
+
+// limiter is a token bucket that lets requests through at a steady rate,
+// allowing short bursts. Tokens are reserved up front, so concurrent callers
+// are let through in the order they asked.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket size
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter creates a limiter for the given settings, or nil when requests
+// aren't limited
+func newLimiter(config types.RateLimitConfig) *limiter {
+	if config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(max(config.Burst, 1))
+	return &limiter{
+		rate:   config.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait until it's available
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until the next request may be made or ctx is canceled.
+// A nil limiter never blocks.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	return sleep(ctx, l.reserve())
+}
@@ -0,0 +1,70 @@
+package arr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"score-checker/internal/types"
+)
+
+func TestNewLimiter(t *testing.T) {
+	if l := newLimiter(types.RateLimitConfig{}); l != nil {
+		t.Error("expected no limiter without a rate")
+	}
+	// A nil limiter never blocks
+	var l *limiter
+	l.wait(context.Background())
+
+	l = newLimiter(types.RateLimitConfig{RequestsPerSecond: 2})
+	if l == nil || l.burst != 1 {
+		t.Fatalf("expected a limiter with a burst of at least 1, got %+v", l)
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	l := newLimiter(types.RateLimitConfig{RequestsPerSecond: 10, Burst: 3})
+
+	// The burst goes through at once
+	for i := range 3 {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d: expected no delay within the burst, got %v", i, delay)
+		}
+	}
+
+	// After that requests are spaced out at the rate
+	for i, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+		delay := l.reserve()
+		if delay < expected-10*time.Millisecond || delay > expected {
+			t.Errorf("request %d: expected a delay of about %v, got %v", i, expected, delay)
+		}
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{
+		Name:      "test",
+		BaseURL:   server.URL,
+		APIKey:    "test-api-key",
+		RateLimit: types.RateLimitConfig{RequestsPerSecond: 50, Burst: 1},
+	}, APIv3)
+
+	// The first request uses the burst, the other four wait 20ms each
+	start := time.Now()
+	for range 5 {
+		var episodes []types.Episode
+		if err := client.Get(context.Background(), "/episode", nil, &episodes); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, 5 requests took %v", elapsed)
+	}
+}
@@ -0,0 +1,122 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// send makes a request with client, retrying transient failures according to
+// the instance's retry policy. Every attempt waits for the instance's rate limit.
+// newRequest is called for every attempt so the body can be sent again.
+// Requests that aren't idempotent are only retried when the server can't
+// have acted on them. No attempt is started once ctx is canceled.
+func (c *Client) send(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error), idempotent bool) (*http.Response, error) {
+	attempts := max(c.config.Retry.Attempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if attempt >= attempts || !retryable(resp, err, idempotent) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = after
+				if c.config.Retry.MaxDelay > 0 {
+					wait = min(wait, c.config.Retry.MaxDelay)
+				}
+			}
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d, returning early with ctx's error once ctx is canceled
+func sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil || d <= 0 {
+		return err
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable reports whether a failed attempt is worth repeating. Network
+// errors and 429 and 5xx responses are transient. Requests that aren't
+// idempotent are only repeated if they never reached the server or the
+// server turned them away without handling them.
+func retryable(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		var opErr *net.OpError
+		return idempotent || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= 500:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// backoff returns how long to wait before retrying after the given attempt:
+// the base delay doubled for each earlier retry, capped at the maximum delay,
+// with jitter so instances don't retry in lockstep
+func (c *Client) backoff(attempt int) time.Duration {
+	delay, maxDelay := c.config.Retry.Delay, c.config.Retry.MaxDelay
+	for i := 1; i < attempt && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 {
+		delay = min(delay, maxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Wait somewhere between half and all of the delay
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package arr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"score-checker/internal/types"
+)
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		post          bool
+		attempts      int
+		statuses      []int // responses for consecutive attempts, the last one repeats
+		retryAfter    string
+		expectedCalls int32
+		expectError   bool
+	}{
+		{name: "GET succeeds after transient errors", attempts: 3, statuses: []int{503, 502, 200}, expectedCalls: 3},
+		{name: "GET gives up after all attempts", attempts: 2, statuses: []int{500}, expectedCalls: 2, expectError: true},
+		{name: "GET retries rate limiting", attempts: 3, statuses: []int{429, 200}, retryAfter: "0", expectedCalls: 2},
+		{name: "GET does not retry client errors", attempts: 3, statuses: []int{404}, expectedCalls: 1, expectError: true},
+		{name: "retries disabled", attempts: 1, statuses: []int{503, 200}, expectedCalls: 1, expectError: true},
+		{name: "POST is not retried after a server error", post: true, attempts: 3, statuses: []int{500, 201}, expectedCalls: 1, expectError: true},
+		{name: "POST is retried when rate limited", post: true, attempts: 3, statuses: []int{429, 201}, expectedCalls: 2},
+		{name: "POST is retried when unavailable", post: true, attempts: 3, statuses: []int{503, 201}, expectedCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := int(calls.Add(1))
+				status := tt.statuses[min(call, len(tt.statuses))-1]
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				if r.Method == "POST" {
+					_, _ = w.Write([]byte(`{"id": 1, "status": "queued"}`))
+				} else {
+					_, _ = w.Write([]byte(`[]`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{
+				Name:    "test",
+				BaseURL: server.URL,
+				APIKey:  "test-api-key",
+				Retry:   types.RetryConfig{Attempts: tt.attempts, Delay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+			}, APIv3)
+
+			var err error
+			if tt.post {
+				_, err = client.Command(context.Background(), map[string]any{"name": "MoviesSearch"})
+			} else {
+				var movies []any
+				err = client.Get(context.Background(), "/movie", nil, &movies)
+			}
+
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if calls.Load() != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	// Grab a free port and close it again so connections are refused
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	client := NewClient(types.ServiceConfig{
+		Name:    "test",
+		BaseURL: baseURL,
+		APIKey:  "test-api-key",
+		Retry:   types.RetryConfig{Attempts: 3, Delay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	}, APIv3)
+
+	// Two retries wait at least half the delay each
+	start := time.Now()
+	if _, err := client.Command(context.Background(), map[string]any{"name": "MoviesSearch"}); err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("expected a refused POST to be retried twice, gave up after %v", elapsed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	client := NewClient(types.ServiceConfig{
+		Retry: types.RetryConfig{Attempts: 5, Delay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond},
+	}, APIv3)
+
+	tests := []struct {
+		attempt  int
+		maxDelay time.Duration
+	}{
+		{attempt: 1, maxDelay: 100 * time.Millisecond},
+		{attempt: 2, maxDelay: 200 * time.Millisecond},
+		{attempt: 3, maxDelay: 300 * time.Millisecond},
+		{attempt: 10, maxDelay: 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			wait := client.backoff(tt.attempt)
+			if wait < tt.maxDelay/2 || wait > tt.maxDelay {
+				t.Fatalf("attempt %d: expected wait between %v and %v, got %v", tt.attempt, tt.maxDelay/2, tt.maxDelay, wait)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "5", expected: 5 * time.Second, ok: true},
+		{value: "-3", expected: 0, ok: true},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), expected: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("retryAfter(%q): expected %v (ok: %v), got %v (ok: %v)", tt.value, tt.expected, tt.ok, got, ok)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(future); !ok || got <= 0 || got > time.Minute {
+		t.Errorf("retryAfter(%q): expected up to a minute, got %v (ok: %v)", future, got, ok)
+	}
+}
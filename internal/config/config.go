@@ -1,17 +1,30 @@
 package config
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 
+	"score-checker/internal/constants"
+	"score-checker/internal/state"
 	"score-checker/internal/types"
 )
 
@@ -43,6 +56,9 @@ func (h *customHandler) WithGroup(name string) slog.Handler {
 	return h
 }
 
+// logFile is the log file opened by the last Load, closed by CloseLog
+var logFile *os.File
+
 // initLogger initializes slog with console output and custom format
 func initLogger() {
 	handler := &customHandler{
@@ -59,58 +75,508 @@ func initLoggerWithFile(logDir string) error {
 	}
 
 	logFilePath := filepath.Join(logDir, "score-checker.log")
-	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return err
 	}
 
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	multiWriter := io.MultiWriter(os.Stdout, file)
 	handler := &customHandler{
 		writer: multiWriter,
 		level:  slog.LevelDebug,
 	}
 	slog.SetDefault(slog.New(handler))
 
+	// Every run loads the configuration again, don't leak the previous file
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = file
+
 	return nil
 }
 
-// Init initializes the configuration system
+// CloseLog flushes and closes the log file, logging to the console only from then on
+func CloseLog() {
+	if logFile == nil {
+		return
+	}
+	initLogger()
+	_ = logFile.Sync()
+	_ = logFile.Close()
+	logFile = nil
+}
+
+// Init initializes the configuration system, reading the config file given
+// by SCORECHECK_CONFIG or found in the default locations
 func Init() {
+	InitWithConfig("")
+}
+
+// InitWithConfig initializes the configuration system. path is a config file,
+// or a directory of config files, taking precedence over SCORECHECK_CONFIG.
+// Without either, config.yaml, config.json or config.toml is looked for in the
+// working directory and /etc/score-checker/, along with a conf.d directory
+// next to it.
+func InitWithConfig(path string) {
 	viper.SetDefault("triggersearch", false)
 	viper.SetDefault("batchsize", 5)
 	viper.SetDefault("interval", "1h")
 	viper.SetDefault("loglevel", "INFO")
+	viper.SetDefault("threshold", 0)
+	viper.SetDefault("mode", constants.ModeThreshold)
+	viper.SetDefault("cooldown", "0s")
+	viper.SetDefault("order", constants.OrderDefault)
+	viper.SetDefault("monitoredonly", true)
+	viper.SetDefault("concurrency", 4)
+	viper.SetDefault("workers", 4)
+	viper.SetDefault("retryattempts", 3)
+	viper.SetDefault("retrydelay", "1s")
+	viper.SetDefault("retrymaxdelay", "30s")
+	viper.SetDefault("ratelimit", 0)
+	viper.SetDefault("rateburst", 5)
+	viper.SetDefault("graceperiod", "5s")
+	viper.SetDefault("timeout", "30s")
 
 	// Read config from environment variables
 	viper.AutomaticEnv()
-	viper.SetEnvPrefix("SCORECHECK")
+	viper.SetEnvPrefix(envPrefix)
 
 	// Try to read config file
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("/etc/score-checker/")
-
-	if err := viper.ReadInConfig(); err != nil {
-		// Use fmt.Printf here since logger isn't initialized yet
+	if path == "" {
+		path = os.Getenv(configEnv)
+	}
+	configPath = path
+	configFile, configDir, configFiles = "", "", nil
+	configErr = nil
+
+	// Use fmt.Printf here since logger isn't initialized yet
+	err := readConfig()
+	switch {
+	case errors.Is(err, errNoConfig) && configPath == "":
+		fmt.Printf("Config file not read: no config file found in %s\n", strings.Join(configPaths, ", "))
+	case err != nil:
+		// A config given explicitly has to be read, Load stops on this
+		configErr = err
 		fmt.Printf("Config file not read: %v\n", err)
+	default:
+		fmt.Printf("Using config file(s): %s\n", strings.Join(configFiles, ", "))
+	}
+}
+
+func parseCooldown(value string) (time.Duration, error) {
+	cooldown, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if cooldown < 0 {
+		return 0, fmt.Errorf("cooldown must not be negative")
+	}
+	return cooldown, nil
+}
+
+func parseGracePeriod(value string) (time.Duration, error) {
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if gracePeriod < 0 {
+		return 0, fmt.Errorf("grace period must not be negative")
+	}
+	return gracePeriod, nil
+}
+
+// parseRetry builds a retry policy, starting from defaults and applying
+// whichever of attempts, delay and max delay are set
+func parseRetry(defaults types.RetryConfig, attempts, delay, maxDelay any) (types.RetryConfig, error) {
+	retry := defaults
+	if attempts != nil {
+		n, err := parseLimit(attempts)
+		if err != nil {
+			return retry, fmt.Errorf("retryattempts: %w", err)
+		}
+		retry.Attempts = n
+	}
+	for _, d := range []struct {
+		key   string
+		value any
+		dest  *time.Duration
+	}{
+		{"retrydelay", delay, &retry.Delay},
+		{"retrymaxdelay", maxDelay, &retry.MaxDelay},
+	} {
+		if d.value == nil {
+			continue
+		}
+		duration, err := time.ParseDuration(cast.ToString(d.value))
+		if err != nil {
+			return retry, fmt.Errorf("%s: %w", d.key, err)
+		}
+		if duration < 0 {
+			return retry, fmt.Errorf("%s must not be negative", d.key)
+		}
+		*d.dest = duration
+	}
+	if retry.MaxDelay < retry.Delay {
+		return retry, fmt.Errorf("retrymaxdelay %v is less than retrydelay %v", retry.MaxDelay, retry.Delay)
+	}
+	return retry, nil
+}
+
+// parseRateLimit builds a rate limit, starting from defaults and applying
+// the requests per second and burst if set
+func parseRateLimit(defaults types.RateLimitConfig, rate, burst any) (types.RateLimitConfig, error) {
+	limit := defaults
+	if rate != nil {
+		perSecond, err := cast.ToFloat64E(rate)
+		if err != nil {
+			return limit, fmt.Errorf("ratelimit: %w", err)
+		}
+		if perSecond < 0 {
+			return limit, fmt.Errorf("ratelimit must not be negative")
+		}
+		limit.RequestsPerSecond = perSecond
+	}
+	if burst != nil {
+		n, err := parseLimit(burst)
+		if err != nil {
+			return limit, fmt.Errorf("rateburst: %w", err)
+		}
+		limit.Burst = n
+	}
+	return limit, nil
+}
+
+// parseTransport builds the connection settings of an instance, starting from
+// defaults and applying the timeout, ca_file, client_cert, client_key,
+// insecure_skip_verify and proxy keys of raw. Certificates are loaded here so
+// problems with them are reported on startup.
+func parseTransport(defaults types.TransportConfig, raw map[string]any) (types.TransportConfig, error) {
+	transport := defaults
+	if value := raw["timeout"]; value != nil {
+		timeout, err := time.ParseDuration(cast.ToString(value))
+		if err != nil {
+			return transport, fmt.Errorf("timeout: %w", err)
+		}
+		if timeout < 0 {
+			return transport, fmt.Errorf("timeout must not be negative")
+		}
+		transport.Timeout = timeout
+	}
+
+	// TLS settings are only set up when the instance changes one of them
+	var tlsConfig *tls.Config
+	if defaults.TLS != nil {
+		tlsConfig = defaults.TLS.Clone()
+	}
+	tlsSettings := func() *tls.Config {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		return tlsConfig
+	}
+
+	if value := raw["ca_file"]; value != nil {
+		file := cast.ToString(value)
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return transport, fmt.Errorf("ca_file: %w", err)
+		}
+		// Trust the private CA on top of the system's CAs
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return transport, fmt.Errorf("ca_file: no certificates found in %s", file)
+		}
+		tlsSettings().RootCAs = pool
+	}
+
+	certFile, keyFile := raw["client_cert"], raw["client_key"]
+	if (certFile == nil) != (keyFile == nil) {
+		return transport, fmt.Errorf("client_cert and client_key must be set together")
+	}
+	if certFile != nil {
+		cert, err := tls.LoadX509KeyPair(cast.ToString(certFile), cast.ToString(keyFile))
+		if err != nil {
+			return transport, fmt.Errorf("client_cert: %w", err)
+		}
+		tlsSettings().Certificates = []tls.Certificate{cert}
+	}
+
+	if value := raw["insecure_skip_verify"]; value != nil {
+		insecure, err := cast.ToBoolE(value)
+		if err != nil {
+			return transport, fmt.Errorf("insecure_skip_verify: %w", err)
+		}
+		tlsSettings().InsecureSkipVerify = insecure
+	}
+	transport.TLS = tlsConfig
+
+	if value := raw["proxy"]; value != nil {
+		proxy, err := url.Parse(cast.ToString(value))
+		if err != nil {
+			return transport, fmt.Errorf("proxy: %w", err)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return transport, fmt.Errorf("proxy: unsupported scheme %q (expected http, https, socks5 or socks5h)", proxy.Scheme)
+		}
+		if proxy.Host == "" {
+			return transport, fmt.Errorf("proxy: missing host in %q", value)
+		}
+		transport.Proxy = proxy
+	}
+	return transport, nil
+}
+
+// apiKeySources are the settings an instance's API key can be read from
+var apiKeySources = []string{"apikey", "apikey_file", "apikey_env", "apikey_command"}
+
+// apiKeyCommandTimeout bounds how long apikey_command may run
+const apiKeyCommandTimeout = 30 * time.Second
+
+// resolveAPIKey returns an instance's API key from exactly one of apikey,
+// apikey_file, apikey_env or apikey_command. Errors never include the key.
+func resolveAPIKey(instance map[string]any) (string, error) {
+	var set []string
+	for _, key := range apiKeySources {
+		if instance[key] != nil {
+			set = append(set, key)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return "", fmt.Errorf("missing apikey (set one of %s)", strings.Join(apiKeySources, ", "))
+	case 1:
+	default:
+		return "", fmt.Errorf("has more than one API key setting (%s), only one may be set", strings.Join(set, ", "))
+	}
+
+	source := set[0]
+	value := instance[source]
+	var apiKey string
+	switch source {
+	case "apikey":
+		key, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("has invalid apikey: expected a string")
+		}
+		return key, nil
+	case "apikey_file":
+		file := cast.ToString(value)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("has unreadable apikey_file: %w", err)
+		}
+		apiKey = strings.TrimSpace(string(data))
+	case "apikey_env":
+		name := cast.ToString(value)
+		apiKey = strings.TrimSpace(os.Getenv(name))
+		if apiKey == "" {
+			return "", fmt.Errorf("has apikey_env %s, which is not set", name)
+		}
+	case "apikey_command":
+		key, err := runAPIKeyCommand(value)
+		if err != nil {
+			return "", fmt.Errorf("has failing apikey_command: %w", err)
+		}
+		apiKey = key
+	}
+	if apiKey == "" {
+		return "", fmt.Errorf("has an empty API key from %s", source)
+	}
+	return apiKey, nil
+}
+
+// runAPIKeyCommand runs apikey_command and returns its trimmed output. A
+// string is run by the shell, a list is run as the program and its arguments.
+func runAPIKeyCommand(value any) (string, error) {
+	var args []string
+	if command, ok := value.(string); ok {
+		args = []string{"sh", "-c", command}
 	} else {
-		// Use fmt.Printf here since logger isn't initialized yet
-		fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
+		list, err := toStringSlice(value)
+		if err != nil {
+			return "", err
+		}
+		args = list
 	}
+	if len(args) == 0 || args[0] == "" {
+		return "", fmt.Errorf("empty command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// Only stderr is shown, stdout may hold part of the key
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
 }
 
-func parseInterval() time.Duration {
-	interval, err := time.ParseDuration(viper.GetString("interval"))
+// parseBasicAuth reads a basic_auth block with a username and password
+func parseBasicAuth(value any) (*types.BasicAuth, error) {
+	raw, err := cast.ToStringMapE(value)
+	if err != nil {
+		return nil, err
+	}
+	auth := &types.BasicAuth{
+		Username: cast.ToString(raw["username"]),
+		Password: cast.ToString(raw["password"]),
+	}
+	if auth.Username == "" {
+		return nil, fmt.Errorf("missing username")
+	}
+	return auth, nil
+}
+
+// parseHeaders reads a map of extra request headers. The config file
+// lowercases keys, so header names are put back in their canonical form.
+func parseHeaders(value any) (map[string]string, error) {
+	raw, err := cast.ToStringMapStringE(value)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string, len(raw))
+	for name, value := range raw {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header %s: value must not contain line breaks", name)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers, nil
+}
+
+func parseMode(value string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	switch mode {
+	case constants.ModeThreshold, constants.ModeMinFormatScore, constants.ModeCutoffFormatScore:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown mode %q (expected %s, %s or %s)", value,
+		constants.ModeThreshold, constants.ModeMinFormatScore, constants.ModeCutoffFormatScore)
+}
+
+func parseOrder(value string) (string, error) {
+	order := strings.ToLower(strings.TrimSpace(value))
+	switch order {
+	case constants.OrderDefault, constants.OrderLowestScore, constants.OrderOldestFile,
+		constants.OrderNewest, constants.OrderRandom, constants.OrderRoundRobin:
+		return order, nil
+	}
+	return "", fmt.Errorf("unknown order %q (expected %s, %s, %s, %s, %s or %s)", value,
+		constants.OrderDefault, constants.OrderLowestScore, constants.OrderOldestFile,
+		constants.OrderNewest, constants.OrderRandom, constants.OrderRoundRobin)
+}
+
+// parseLimit reads a concurrency limit, which must be at least 1
+func parseLimit(value any) (int, error) {
+	limit, err := cast.ToIntE(value)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 1 {
+		return 0, fmt.Errorf("must be at least 1, got %d", limit)
+	}
+	return limit, nil
+}
+
+// toStringSlice converts a list (or a single string) from the config to strings
+func toStringSlice(value any) ([]string, error) {
+	if single, ok := value.(string); ok {
+		return []string{single}, nil
+	}
+	return cast.ToStringSliceE(value)
+}
+
+// compilePatterns compiles a list of regular expressions from the config
+func compilePatterns(value any) ([]*regexp.Regexp, error) {
+	patterns, err := toStringSlice(value)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// parseExclude reads an exclude block listing titles, title patterns,
+// internal IDs and external IDs that should never be checked
+func parseExclude(raw any) (types.ExcludeConfig, error) {
+	var exclude types.ExcludeConfig
+	if raw == nil {
+		return exclude, nil
+	}
+
+	block, err := cast.ToStringMapE(raw)
 	if err != nil {
-		log.Fatalf("Invalid interval format: %v", err)
+		return exclude, fmt.Errorf("exclude must be a map: %w", err)
+	}
+
+	for key, value := range block {
+		switch strings.ToLower(key) {
+		case "titles":
+			exclude.Titles, err = toStringSlice(value)
+		case "patterns":
+			exclude.Patterns, err = compilePatterns(value)
+		case "ids":
+			exclude.IDs, err = cast.ToIntSliceE(value)
+		case "tvdbids":
+			exclude.TvdbIDs, err = cast.ToIntSliceE(value)
+		case "tmdbids":
+			exclude.TmdbIDs, err = cast.ToIntSliceE(value)
+		case "imdbids":
+			exclude.ImdbIDs, err = toStringSlice(value)
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return exclude, fmt.Errorf("invalid exclude %s: %w", key, err)
+		}
+	}
+
+	return exclude, nil
+}
+
+// mergeExclude combines two exclude lists
+func mergeExclude(a, b types.ExcludeConfig) types.ExcludeConfig {
+	return types.ExcludeConfig{
+		Titles:   append(slices.Clone(a.Titles), b.Titles...),
+		Patterns: append(slices.Clone(a.Patterns), b.Patterns...),
+		IDs:      append(slices.Clone(a.IDs), b.IDs...),
+		TvdbIDs:  append(slices.Clone(a.TvdbIDs), b.TvdbIDs...),
+		TmdbIDs:  append(slices.Clone(a.TmdbIDs), b.TmdbIDs...),
+		ImdbIDs:  append(slices.Clone(a.ImdbIDs), b.ImdbIDs...),
 	}
-	return interval
 }
 
+// determineLogDir returns where the log and state files go: next to the
+// config file, or the working directory if there is none. Files never go in
+// a conf.d directory, where they'd be read as config.
 func determineLogDir() string {
-	if configFile := viper.ConfigFileUsed(); configFile != "" {
-		return filepath.Dir(configFile)
+	if file := viper.ConfigFileUsed(); file != "" && filepath.Dir(file) != filepath.Clean(configDir) {
+		return filepath.Dir(file)
 	}
 	return "."
 }
@@ -130,7 +596,12 @@ func generateInstanceName(index int) string {
 	return "instance" + string(rune('0'+index))
 }
 
-func parseServiceInstance(instance map[string]any, index int, serviceName string) types.ServiceConfig {
+// parseServiceInstance builds a ServiceConfig from a raw config entry.
+// Settings the entry doesn't specify are inherited from defaults. Every
+// problem with the entry is returned, not just the first.
+func parseServiceInstance(instance map[string]any, index int, serviceName string, defaults types.ServiceConfig) (types.ServiceConfig, []error) {
+	var errs []error
+
 	name, ok := instance["name"].(string)
 	if !ok {
 		name = generateInstanceName(index)
@@ -138,49 +609,300 @@ func parseServiceInstance(instance map[string]any, index int, serviceName string
 
 	baseURL, ok := instance["baseurl"].(string)
 	if !ok {
-		log.Fatalf("%s instance '%s' missing baseurl", serviceName, name)
+		errs = append(errs, fmt.Errorf("%s instance '%s' missing baseurl", serviceName, name))
 	}
 
-	apiKey, ok := instance["apikey"].(string)
-	if !ok {
-		log.Fatalf("%s instance '%s' missing apikey", serviceName, name)
+	apiKey, err := resolveAPIKey(instance)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s instance '%s' %w", serviceName, name, err))
+	}
+
+	config := defaults
+	config.Name = name
+	config.BaseURL = baseURL
+	config.APIKey = apiKey
+
+	if value, ok := instance["threshold"]; ok {
+		threshold, err := cast.ToIntE(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid threshold: %w", serviceName, name, err))
+		}
+		config.Threshold = threshold
+	}
+
+	if value, ok := instance["mode"].(string); ok {
+		mode, err := parseMode(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid mode: %w", serviceName, name, err))
+		}
+		config.Mode = mode
+	}
+
+	if value, ok := instance["cooldown"]; ok {
+		cooldown, err := parseCooldown(cast.ToString(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid cooldown: %w", serviceName, name, err))
+		}
+		config.Cooldown = cooldown
+	}
+
+	if value, ok := instance["order"].(string); ok {
+		order, err := parseOrder(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid order: %w", serviceName, name, err))
+		}
+		config.Order = order
+	}
+
+	if value, ok := instance["monitoredonly"]; ok {
+		monitoredOnly, err := cast.ToBoolE(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid monitoredonly: %w", serviceName, name, err))
+		}
+		config.MonitoredOnly = monitoredOnly
+	}
+
+	if value, ok := instance["exclude"]; ok {
+		exclude, err := parseExclude(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid exclude: %w", serviceName, name, err))
+		}
+		config.Exclude = mergeExclude(defaults.Exclude, exclude)
+	}
+
+	if value, ok := instance["include_tags"]; ok {
+		tags, err := toStringSlice(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid include_tags: %w", serviceName, name, err))
+		}
+		config.IncludeTags = tags
+	}
+
+	if value, ok := instance["exclude_tags"]; ok {
+		tags, err := toStringSlice(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid exclude_tags: %w", serviceName, name, err))
+		}
+		config.ExcludeTags = tags
+	}
+
+	if value, ok := instance["workers"]; ok {
+		workers, err := parseLimit(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid workers: %w", serviceName, name, err))
+		}
+		config.Workers = workers
+	}
+
+	retry, err := parseRetry(defaults.Retry, instance["retryattempts"], instance["retrydelay"], instance["retrymaxdelay"])
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s instance '%s' has invalid retry settings: %w", serviceName, name, err))
+	}
+	config.Retry = retry
+
+	rateLimit, err := parseRateLimit(defaults.RateLimit, instance["ratelimit"], instance["rateburst"])
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s instance '%s' has invalid rate limit: %w", serviceName, name, err))
+	}
+	config.RateLimit = rateLimit
+
+	transport, err := parseTransport(defaults.Transport, instance)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s instance '%s' has invalid connection settings: %w", serviceName, name, err))
+	}
+	config.Transport = transport
+
+	if value, ok := instance["basic_auth"]; ok {
+		auth, err := parseBasicAuth(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid basic_auth: %w", serviceName, name, err))
+		}
+		config.BasicAuth = auth
 	}
 
-	return types.ServiceConfig{
-		Name:    name,
-		BaseURL: baseURL,
-		APIKey:  apiKey,
+	if value, ok := instance["headers"]; ok {
+		headers, err := parseHeaders(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid headers: %w", serviceName, name, err))
+		}
+		config.Headers = headers
+	}
+
+	if value, ok := instance["apikey_in_query"]; ok {
+		inQuery, err := cast.ToBoolE(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s instance '%s' has invalid apikey_in_query: %w", serviceName, name, err))
+		}
+		config.APIKeyInQuery = inQuery
 	}
+
+	return config, errs
 }
 
-func loadServiceInstances(key, serviceName string) []types.ServiceConfig {
-	var instances []types.ServiceConfig
+// rawServiceInstances returns the raw entries of a service's instances from
+// the config file and indexed environment variables, the environment taking
+// precedence
+func rawServiceInstances(key, serviceName string) ([]map[string]any, error) {
 	var serviceConfig []map[string]any
+	if err := viper.UnmarshalKey(key, &serviceConfig); err != nil {
+		return nil, fmt.Errorf("Invalid %s instances: %w", serviceName, err)
+	}
+	serviceConfig, err := loadEnvInstances(key, serviceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s instances: %w", serviceName, err)
+	}
+	return serviceConfig, nil
+}
 
-	if err := viper.UnmarshalKey(key, &serviceConfig); err == nil {
-		for i, instance := range serviceConfig {
-			config := parseServiceInstance(instance, i, serviceName)
-			instances = append(instances, config)
-		}
+// loadServiceInstances loads the instances of a service from the config file
+// and indexed environment variables
+func loadServiceInstances(key, serviceName string, defaults types.ServiceConfig) ([]types.ServiceConfig, []error) {
+	var instances []types.ServiceConfig
+
+	serviceConfig, err := rawServiceInstances(key, serviceName)
+	if err != nil {
+		return nil, []error{err}
 	}
 
-	return instances
+	var errs []error
+	for i, instance := range serviceConfig {
+		config, instanceErrs := parseServiceInstance(instance, i, serviceName, defaults)
+		instances = append(instances, config)
+		errs = append(errs, instanceErrs...)
+	}
+
+	return instances, errs
 }
 
-// Load loads configuration using Viper
+// Load loads configuration using Viper, exiting with every problem found if
+// the configuration is invalid
 func Load() types.Config {
-	interval := parseInterval()
+	config, err := check()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 	setupLogging()
+	return config
+}
+
+// build resolves the configuration from Viper, collecting every problem
+// instead of stopping at the first
+func build() (types.Config, []error) {
+	var errs []error
+	fail := func(format string, err error) {
+		errs = append(errs, fmt.Errorf(format, err))
+	}
+	if configErr != nil {
+		fail("Config file not read: %w", configErr)
+	}
+
+	interval, err := time.ParseDuration(viper.GetString("interval"))
+	if err != nil {
+		fail("Invalid interval format: %w", err)
+	}
+	mode, err := parseMode(viper.GetString("mode"))
+	if err != nil {
+		fail("Invalid mode: %w", err)
+	}
+	cooldown, err := parseCooldown(viper.GetString("cooldown"))
+	if err != nil {
+		fail("Invalid cooldown format: %w", err)
+	}
+	order, err := parseOrder(viper.GetString("order"))
+	if err != nil {
+		fail("Invalid order: %w", err)
+	}
+	exclude, err := parseExclude(viper.Get("exclude"))
+	if err != nil {
+		fail("Invalid exclude: %w", err)
+	}
+	concurrency, err := parseLimit(viper.Get("concurrency"))
+	if err != nil {
+		fail("Invalid concurrency: %w", err)
+	}
+	workers, err := parseLimit(viper.Get("workers"))
+	if err != nil {
+		fail("Invalid workers: %w", err)
+	}
+	retry, err := parseRetry(types.RetryConfig{}, viper.Get("retryattempts"), viper.Get("retrydelay"), viper.Get("retrymaxdelay"))
+	if err != nil {
+		fail("Invalid retry settings: %w", err)
+	}
+	rateLimit, err := parseRateLimit(types.RateLimitConfig{}, viper.Get("ratelimit"), viper.Get("rateburst"))
+	if err != nil {
+		fail("Invalid rate limit: %w", err)
+	}
+	gracePeriod, err := parseGracePeriod(viper.GetString("graceperiod"))
+	if err != nil {
+		fail("Invalid grace period: %w", err)
+	}
+	transport, err := parseTransport(types.TransportConfig{}, map[string]any{"timeout": viper.Get("timeout")})
+	if err != nil {
+		fail("Invalid connection settings: %w", err)
+	}
+	batchSize, err := cast.ToIntE(viper.Get("batchsize"))
+	if err != nil {
+		fail("Invalid batch size: %w", err)
+	}
+	threshold, err := cast.ToIntE(viper.Get("threshold"))
+	if err != nil {
+		fail("Invalid threshold: %w", err)
+	}
+	triggerSearch, err := cast.ToBoolE(viper.Get("triggersearch"))
+	if err != nil {
+		fail("Invalid triggersearch: %w", err)
+	}
+	monitoredOnly, err := cast.ToBoolE(viper.Get("monitoredonly"))
+	if err != nil {
+		fail("Invalid monitoredonly: %w", err)
+	}
 
 	config := types.Config{
-		TriggerSearch: viper.GetBool("triggersearch"),
-		BatchSize:     viper.GetInt("batchsize"),
+		TriggerSearch: triggerSearch,
+		BatchSize:     batchSize,
 		Interval:      interval,
 		LogLevel:      viper.GetString("loglevel"),
+		Threshold:     threshold,
+		Mode:          mode,
+		StateFile:     filepath.Join(determineLogDir(), state.FileName),
+		Cooldown:      cooldown,
+		Order:         order,
+		MonitoredOnly: monitoredOnly,
+		Exclude:       exclude,
+		Concurrency:   concurrency,
+		Workers:       workers,
+		Retry:         retry,
+		RateLimit:     rateLimit,
+		GracePeriod:   gracePeriod,
+		Timeout:       transport.Timeout,
 	}
 
-	config.SonarrInstances = loadServiceInstances("sonarr", "Sonarr")
-	config.RadarrInstances = loadServiceInstances("radarr", "Radarr")
+	defaults := types.ServiceConfig{
+		Threshold:     config.Threshold,
+		Mode:          config.Mode,
+		Cooldown:      config.Cooldown,
+		Order:         config.Order,
+		MonitoredOnly: config.MonitoredOnly,
+		Exclude:       config.Exclude,
+		Workers:       config.Workers,
+		Retry:         config.Retry,
+		RateLimit:     config.RateLimit,
+		GracePeriod:   config.GracePeriod,
+		Transport:     transport,
+	}
+	for _, service := range []struct {
+		key, name string
+		dest      *[]types.ServiceConfig
+	}{
+		{"sonarr", "Sonarr", &config.SonarrInstances},
+		{"radarr", "Radarr", &config.RadarrInstances},
+		{"lidarr", "Lidarr", &config.LidarrInstances},
+		{"readarr", "Readarr", &config.ReadarrInstances},
+	} {
+		instances, instanceErrs := loadServiceInstances(service.key, service.name, defaults)
+		*service.dest = instances
+		errs = append(errs, instanceErrs...)
+	}
 
-	return config
+	return config, errs
 }
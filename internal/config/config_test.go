@@ -5,11 +5,16 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"score-checker/internal/constants"
+	"score-checker/internal/testhelpers"
+	"score-checker/internal/types"
 )
 
 func TestInit(t *testing.T) {
@@ -28,6 +33,12 @@ func TestInit(t *testing.T) {
 	if viper.GetString("interval") != "1h" {
 		t.Error("expected interval default to be '1h'")
 	}
+	if viper.GetInt("threshold") != 0 {
+		t.Error("expected threshold default to be 0")
+	}
+	if viper.GetString("mode") != constants.ModeThreshold {
+		t.Errorf("expected mode default to be %q", constants.ModeThreshold)
+	}
 }
 
 func TestLoadWithDefaults(t *testing.T) {
@@ -47,6 +58,12 @@ func TestLoadWithDefaults(t *testing.T) {
 	if cfg.Interval != time.Hour {
 		t.Errorf("expected Interval to be 1 hour, got %v", cfg.Interval)
 	}
+	if cfg.Timeout != 30*time.Second {
+		t.Errorf("expected Timeout to be 30 seconds, got %v", cfg.Timeout)
+	}
+	if cfg.GracePeriod != 5*time.Second {
+		t.Errorf("expected GracePeriod to be 5 seconds, got %v", cfg.GracePeriod)
+	}
 	if len(cfg.SonarrInstances) != 0 {
 		t.Error("expected no Sonarr instances by default")
 	}
@@ -119,6 +136,24 @@ func TestLoadWithViperConfig(t *testing.T) {
 	}
 	viper.Set("radarr", radarrInstances)
 
+	// Set up Lidarr instances
+	viper.Set("lidarr", []map[string]interface{}{
+		{
+			"name":    "music",
+			"baseurl": "http://localhost:8686",
+			"apikey":  "test-lidarr-key",
+		},
+	})
+
+	// Set up Readarr instances
+	viper.Set("readarr", []map[string]interface{}{
+		{
+			"name":    "books",
+			"baseurl": "http://localhost:8787",
+			"apikey":  "test-readarr-key",
+		},
+	})
+
 	cfg := Load()
 
 	// Test general config
@@ -162,6 +197,28 @@ func TestLoadWithViperConfig(t *testing.T) {
 	if cfg.RadarrInstances[0].APIKey != "test-radarr-key" {
 		t.Errorf("expected Radarr instance apikey to be 'test-radarr-key', got %q", cfg.RadarrInstances[0].APIKey)
 	}
+
+	// Test Lidarr instances
+	if len(cfg.LidarrInstances) != 1 {
+		t.Fatalf("expected 1 Lidarr instance, got %d", len(cfg.LidarrInstances))
+	}
+	if cfg.LidarrInstances[0].Name != "music" {
+		t.Errorf("expected Lidarr instance name to be 'music', got %q", cfg.LidarrInstances[0].Name)
+	}
+	if cfg.LidarrInstances[0].BaseURL != "http://localhost:8686" {
+		t.Errorf("expected Lidarr instance baseurl to be 'http://localhost:8686', got %q", cfg.LidarrInstances[0].BaseURL)
+	}
+
+	// Test Readarr instances
+	if len(cfg.ReadarrInstances) != 1 {
+		t.Fatalf("expected 1 Readarr instance, got %d", len(cfg.ReadarrInstances))
+	}
+	if cfg.ReadarrInstances[0].Name != "books" {
+		t.Errorf("expected Readarr instance name to be 'books', got %q", cfg.ReadarrInstances[0].Name)
+	}
+	if cfg.ReadarrInstances[0].APIKey != "test-readarr-key" {
+		t.Errorf("expected Readarr instance apikey to be 'test-readarr-key', got %q", cfg.ReadarrInstances[0].APIKey)
+	}
 }
 
 func TestLoadWithDefaultInstanceNames(t *testing.T) {
@@ -208,6 +265,600 @@ func TestLoadInvalidInterval(t *testing.T) {
 	// instead of calling log.Fatalf directly
 }
 
+func TestLoadWithThreshold(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("threshold", -5)
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":      "4k",
+			"baseurl":   "http://localhost:8990",
+			"apikey":    "test-sonarr-4k-key",
+			"threshold": 500,
+		},
+	})
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":      "main",
+			"baseurl":   "http://localhost:7878",
+			"apikey":    "test-radarr-key",
+			"threshold": "100",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.Threshold != -5 {
+		t.Errorf("expected global Threshold to be -5, got %d", cfg.Threshold)
+	}
+	if cfg.SonarrInstances[0].Threshold != -5 {
+		t.Errorf("expected 'main' Sonarr instance to inherit threshold -5, got %d", cfg.SonarrInstances[0].Threshold)
+	}
+	if cfg.SonarrInstances[1].Threshold != 500 {
+		t.Errorf("expected '4k' Sonarr instance threshold to be 500, got %d", cfg.SonarrInstances[1].Threshold)
+	}
+	if cfg.RadarrInstances[0].Threshold != 100 {
+		t.Errorf("expected Radarr instance threshold to be 100, got %d", cfg.RadarrInstances[0].Threshold)
+	}
+}
+
+func TestLoadWithMode(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("mode", "MinFormatScore")
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":    "anime",
+			"baseurl": "http://localhost:8990",
+			"apikey":  "test-sonarr-anime-key",
+			"mode":    "cutoff",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.Mode != constants.ModeMinFormatScore {
+		t.Errorf("expected global Mode to be %q, got %q", constants.ModeMinFormatScore, cfg.Mode)
+	}
+	if cfg.SonarrInstances[0].Mode != constants.ModeMinFormatScore {
+		t.Errorf("expected 'main' instance to inherit mode %q, got %q", constants.ModeMinFormatScore, cfg.SonarrInstances[0].Mode)
+	}
+	if cfg.SonarrInstances[1].Mode != constants.ModeCutoffFormatScore {
+		t.Errorf("expected 'anime' instance mode to be %q, got %q", constants.ModeCutoffFormatScore, cfg.SonarrInstances[1].Mode)
+	}
+}
+
+func TestLoadWithCooldown(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("cooldown", "24h")
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:7878",
+			"apikey":  "test-radarr-key",
+		},
+		{
+			"name":     "4k",
+			"baseurl":  "http://localhost:7879",
+			"apikey":   "test-radarr-4k-key",
+			"cooldown": "168h",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.Cooldown != 24*time.Hour {
+		t.Errorf("expected global Cooldown to be 24h, got %v", cfg.Cooldown)
+	}
+	if cfg.RadarrInstances[0].Cooldown != 24*time.Hour {
+		t.Errorf("expected 'main' instance to inherit cooldown 24h, got %v", cfg.RadarrInstances[0].Cooldown)
+	}
+	if cfg.RadarrInstances[1].Cooldown != 168*time.Hour {
+		t.Errorf("expected '4k' instance cooldown to be 168h, got %v", cfg.RadarrInstances[1].Cooldown)
+	}
+}
+
+func TestParseCooldown(t *testing.T) {
+	if _, err := parseCooldown("-1h"); err == nil {
+		t.Error("expected error for negative cooldown")
+	}
+	if _, err := parseCooldown("soon"); err == nil {
+		t.Error("expected error for unparsable cooldown")
+	}
+	if cooldown, err := parseCooldown("30m"); err != nil || cooldown != 30*time.Minute {
+		t.Errorf("expected 30m, got %v (err: %v)", cooldown, err)
+	}
+}
+
+func TestParseGracePeriod(t *testing.T) {
+	if _, err := parseGracePeriod("-1s"); err == nil {
+		t.Error("expected error for negative grace period")
+	}
+	if _, err := parseGracePeriod("later"); err == nil {
+		t.Error("expected error for unparsable grace period")
+	}
+	if gracePeriod, err := parseGracePeriod("10s"); err != nil || gracePeriod != 10*time.Second {
+		t.Errorf("expected 10s, got %v (err: %v)", gracePeriod, err)
+	}
+}
+
+func TestLoadWithConcurrency(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("concurrency", 2)
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":    "anime",
+			"baseurl": "http://localhost:8990",
+			"apikey":  "test-sonarr-anime-key",
+			"workers": "8",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.Concurrency != 2 {
+		t.Errorf("expected Concurrency to be 2, got %d", cfg.Concurrency)
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("expected default Workers to be 4, got %d", cfg.Workers)
+	}
+	if cfg.SonarrInstances[0].Workers != 4 {
+		t.Errorf("expected 'main' instance to inherit 4 workers, got %d", cfg.SonarrInstances[0].Workers)
+	}
+	if cfg.SonarrInstances[1].Workers != 8 {
+		t.Errorf("expected 'anime' instance to have 8 workers, got %d", cfg.SonarrInstances[1].Workers)
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	if _, err := parseLimit(0); err == nil {
+		t.Error("expected error for zero limit")
+	}
+	if _, err := parseLimit("many"); err == nil {
+		t.Error("expected error for non-numeric limit")
+	}
+	if limit, err := parseLimit("3"); err != nil || limit != 3 {
+		t.Errorf("expected 3, got %d (err: %v)", limit, err)
+	}
+}
+
+func TestLoadWithRetry(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("retrydelay", "2s")
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":          "remote",
+			"baseurl":       "http://remote:8989",
+			"apikey":        "test-sonarr-remote-key",
+			"retryattempts": 5,
+			"retrymaxdelay": "1m",
+		},
+	})
+
+	cfg := Load()
+
+	expected := types.RetryConfig{Attempts: 3, Delay: 2 * time.Second, MaxDelay: 30 * time.Second}
+	if cfg.Retry != expected {
+		t.Errorf("expected global Retry %+v, got %+v", expected, cfg.Retry)
+	}
+	if cfg.SonarrInstances[0].Retry != expected {
+		t.Errorf("expected 'main' instance to inherit Retry %+v, got %+v", expected, cfg.SonarrInstances[0].Retry)
+	}
+	expected = types.RetryConfig{Attempts: 5, Delay: 2 * time.Second, MaxDelay: time.Minute}
+	if cfg.SonarrInstances[1].Retry != expected {
+		t.Errorf("expected 'remote' instance Retry %+v, got %+v", expected, cfg.SonarrInstances[1].Retry)
+	}
+}
+
+func TestParseRetryErrors(t *testing.T) {
+	defaults := types.RetryConfig{Attempts: 3, Delay: time.Second, MaxDelay: 30 * time.Second}
+	tests := []struct {
+		name     string
+		attempts any
+		delay    any
+		maxDelay any
+	}{
+		{name: "zero attempts", attempts: 0},
+		{name: "non-numeric attempts", attempts: "often"},
+		{name: "unparsable delay", delay: "soon"},
+		{name: "negative delay", delay: "-1s"},
+		{name: "max delay below delay", delay: "1m"},
+		{name: "max delay below default delay", maxDelay: "500ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseRetry(defaults, tt.attempts, tt.delay, tt.maxDelay); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestLoadWithRateLimit(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	if cfg := Load(); cfg.RateLimit != (types.RateLimitConfig{RequestsPerSecond: 0, Burst: 5}) {
+		t.Errorf("expected no rate limit by default, got %+v", cfg.RateLimit)
+	}
+
+	viper.Set("ratelimit", 10)
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:7878",
+			"apikey":  "test-radarr-key",
+		},
+		{
+			"name":      "nas",
+			"baseurl":   "http://nas:7878",
+			"apikey":    "test-radarr-nas-key",
+			"ratelimit": "0.5",
+			"rateburst": 1,
+		},
+	})
+
+	cfg := Load()
+
+	expected := types.RateLimitConfig{RequestsPerSecond: 10, Burst: 5}
+	if cfg.RadarrInstances[0].RateLimit != expected {
+		t.Errorf("expected 'main' instance to inherit rate limit %+v, got %+v", expected, cfg.RadarrInstances[0].RateLimit)
+	}
+	expected = types.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 1}
+	if cfg.RadarrInstances[1].RateLimit != expected {
+		t.Errorf("expected 'nas' instance rate limit %+v, got %+v", expected, cfg.RadarrInstances[1].RateLimit)
+	}
+
+	for _, tt := range []struct{ rate, burst any }{{rate: -1}, {rate: "fast"}, {burst: 0}} {
+		if _, err := parseRateLimit(expected, tt.rate, tt.burst); err == nil {
+			t.Errorf("expected error for rate %v and burst %v", tt.rate, tt.burst)
+		}
+	}
+}
+
+func TestLoadWithTransport(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	certFile, keyFile := testhelpers.WriteTestCertificate(t, t.TempDir())
+
+	viper.Set("timeout", "10s")
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":                 "remote",
+			"baseurl":              "https://sonarr.example.com",
+			"apikey":               "test-sonarr-remote-key",
+			"timeout":              "1m",
+			"ca_file":              certFile,
+			"client_cert":          certFile,
+			"client_key":           keyFile,
+			"insecure_skip_verify": "false",
+			"proxy":                "socks5://proxy.example.com:1080",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.Timeout != 10*time.Second {
+		t.Errorf("expected Timeout to be 10s, got %v", cfg.Timeout)
+	}
+	local := cfg.SonarrInstances[0].Transport
+	if local.Timeout != 10*time.Second || local.TLS != nil || local.Proxy != nil {
+		t.Errorf("expected 'main' instance to only inherit the timeout, got %+v", local)
+	}
+	remote := cfg.SonarrInstances[1].Transport
+	if remote.Timeout != time.Minute {
+		t.Errorf("expected 'remote' instance timeout 1m, got %v", remote.Timeout)
+	}
+	if remote.TLS == nil || remote.TLS.RootCAs == nil || len(remote.TLS.Certificates) != 1 || remote.TLS.InsecureSkipVerify {
+		t.Errorf("expected 'remote' instance to use the CA and client certificate, got %+v", remote.TLS)
+	}
+	if remote.Proxy == nil || remote.Proxy.String() != "socks5://proxy.example.com:1080" {
+		t.Errorf("expected 'remote' instance to use the SOCKS proxy, got %v", remote.Proxy)
+	}
+}
+
+func TestParseTransportErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testhelpers.WriteTestCertificate(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		raw  map[string]any
+	}{
+		{name: "negative timeout", raw: map[string]any{"timeout": "-1s"}},
+		{name: "unparsable timeout", raw: map[string]any{"timeout": "soon"}},
+		{name: "missing CA file", raw: map[string]any{"ca_file": filepath.Join(dir, "missing.pem")}},
+		{name: "CA file without certificates", raw: map[string]any{"ca_file": notPEM}},
+		{name: "client cert without key", raw: map[string]any{"client_cert": certFile}},
+		{name: "client key without cert", raw: map[string]any{"client_key": keyFile}},
+		{name: "mismatched key pair", raw: map[string]any{"client_cert": certFile, "client_key": notPEM}},
+		{name: "invalid insecure_skip_verify", raw: map[string]any{"insecure_skip_verify": "maybe"}},
+		{name: "unsupported proxy scheme", raw: map[string]any{"proxy": "ftp://proxy:21"}},
+		{name: "proxy without host", raw: map[string]any{"proxy": "proxy.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTransport(types.TransportConfig{}, tt.raw); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestLoadWithAuth(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":            "proxied",
+			"baseurl":         "https://sonarr.example.com",
+			"apikey":          "test-sonarr-proxied-key",
+			"basic_auth":      map[string]interface{}{"username": "user", "password": "secret"},
+			"headers":         map[string]interface{}{"CF-Access-Client-Id": "client-id"},
+			"apikey_in_query": true,
+		},
+	})
+
+	cfg := Load()
+
+	local := cfg.SonarrInstances[0]
+	if local.BasicAuth != nil || local.Headers != nil || local.APIKeyInQuery {
+		t.Errorf("expected 'main' instance to use the API key header only, got %+v", local)
+	}
+	proxied := cfg.SonarrInstances[1]
+	if proxied.BasicAuth == nil || *proxied.BasicAuth != (types.BasicAuth{Username: "user", Password: "secret"}) {
+		t.Errorf("expected 'proxied' instance basic auth user:secret, got %+v", proxied.BasicAuth)
+	}
+	if len(proxied.Headers) != 1 || proxied.Headers["Cf-Access-Client-Id"] != "client-id" {
+		t.Errorf("expected 'proxied' instance CF-Access-Client-Id header, got %v", proxied.Headers)
+	}
+	if !proxied.APIKeyInQuery {
+		t.Error("expected 'proxied' instance to send the API key as a query parameter")
+	}
+}
+
+func TestParseAuthErrors(t *testing.T) {
+	for _, value := range []any{"user:secret", map[string]any{"password": "secret"}} {
+		if _, err := parseBasicAuth(value); err == nil {
+			t.Errorf("expected error for basic_auth %v", value)
+		}
+	}
+	for _, value := range []any{"X-Header: value", map[string]any{"X Header": "value"}, map[string]any{"X-Header": "a\r\nb"}} {
+		if _, err := parseHeaders(value); err == nil {
+			t.Errorf("expected error for headers %v", value)
+		}
+	}
+}
+
+func TestLoadWithOrder(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	if cfg := Load(); cfg.Order != constants.OrderDefault {
+		t.Errorf("expected default Order to be %q, got %q", constants.OrderDefault, cfg.Order)
+	}
+
+	viper.Set("order", "Lowest-Score")
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":    "anime",
+			"baseurl": "http://localhost:8990",
+			"apikey":  "test-sonarr-anime-key",
+			"order":   "round-robin",
+		},
+	})
+
+	cfg := Load()
+
+	if cfg.SonarrInstances[0].Order != constants.OrderLowestScore {
+		t.Errorf("expected 'main' instance to inherit order %q, got %q", constants.OrderLowestScore, cfg.SonarrInstances[0].Order)
+	}
+	if cfg.SonarrInstances[1].Order != constants.OrderRoundRobin {
+		t.Errorf("expected 'anime' instance order to be %q, got %q", constants.OrderRoundRobin, cfg.SonarrInstances[1].Order)
+	}
+	if _, err := parseOrder("alphabetical"); err == nil {
+		t.Error("expected error for unknown order")
+	}
+}
+
+func TestLoadWithMonitoredOnly(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:7878",
+			"apikey":  "test-radarr-key",
+		},
+		{
+			"name":          "archive",
+			"baseurl":       "http://localhost:7879",
+			"apikey":        "test-radarr-archive-key",
+			"monitoredonly": false,
+		},
+	})
+
+	cfg := Load()
+
+	if !cfg.MonitoredOnly {
+		t.Error("expected MonitoredOnly to be true by default")
+	}
+	if !cfg.RadarrInstances[0].MonitoredOnly {
+		t.Error("expected 'main' instance to inherit MonitoredOnly")
+	}
+	if cfg.RadarrInstances[1].MonitoredOnly {
+		t.Error("expected 'archive' instance to include unmonitored movies")
+	}
+}
+
+func TestLoadWithExclude(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("exclude", map[string]interface{}{
+		"titles":   []interface{}{"One Piece"},
+		"patterns": []interface{}{"(?i)^planet earth"},
+	})
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+		{
+			"name":    "anime",
+			"baseurl": "http://localhost:8990",
+			"apikey":  "test-sonarr-anime-key",
+			"exclude": map[string]interface{}{
+				"ids":     []interface{}{12, 13},
+				"tvdbids": []interface{}{81189},
+				"imdbids": "tt0903747",
+			},
+		},
+	})
+
+	cfg := Load()
+
+	if len(cfg.Exclude.Titles) != 1 || len(cfg.Exclude.Patterns) != 1 {
+		t.Fatalf("expected global exclude with 1 title and 1 pattern, got %+v", cfg.Exclude)
+	}
+	if len(cfg.SonarrInstances[0].Exclude.Titles) != 1 {
+		t.Errorf("expected 'main' instance to inherit the global exclude, got %+v", cfg.SonarrInstances[0].Exclude)
+	}
+
+	anime := cfg.SonarrInstances[1].Exclude
+	if len(anime.Titles) != 1 || len(anime.Patterns) != 1 {
+		t.Errorf("expected 'anime' instance to keep the global exclude, got %+v", anime)
+	}
+	if len(anime.IDs) != 2 || anime.IDs[1] != 13 {
+		t.Errorf("expected 'anime' instance IDs [12 13], got %v", anime.IDs)
+	}
+	if len(anime.TvdbIDs) != 1 || anime.TvdbIDs[0] != 81189 {
+		t.Errorf("expected 'anime' instance TVDB IDs [81189], got %v", anime.TvdbIDs)
+	}
+	if len(anime.ImdbIDs) != 1 || anime.ImdbIDs[0] != "tt0903747" {
+		t.Errorf("expected 'anime' instance IMDb IDs [tt0903747], got %v", anime.ImdbIDs)
+	}
+}
+
+func TestLoadWithTags(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":         "main",
+			"baseurl":      "http://localhost:8989",
+			"apikey":       "test-sonarr-key",
+			"include_tags": []interface{}{"anime", "kids"},
+			"exclude_tags": "keep",
+		},
+	})
+
+	cfg := Load()
+
+	instance := cfg.SonarrInstances[0]
+	if len(instance.IncludeTags) != 2 || instance.IncludeTags[1] != "kids" {
+		t.Errorf("expected include tags [anime kids], got %v", instance.IncludeTags)
+	}
+	if len(instance.ExcludeTags) != 1 || instance.ExcludeTags[0] != "keep" {
+		t.Errorf("expected exclude tags [keep], got %v", instance.ExcludeTags)
+	}
+}
+
+func TestParseExcludeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  any
+	}{
+		{name: "not a map", raw: "One Piece"},
+		{name: "unknown key", raw: map[string]any{"names": []any{"One Piece"}}},
+		{name: "invalid pattern", raw: map[string]any{"patterns": []any{"(unclosed"}}},
+		{name: "non-numeric ID", raw: map[string]any{"tmdbids": []any{"abc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseExclude(tt.raw); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if _, err := parseMode("bogus"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+	if mode, err := parseMode(" Threshold "); err != nil || mode != constants.ModeThreshold {
+		t.Errorf("expected %q, got %q (err: %v)", constants.ModeThreshold, mode, err)
+	}
+}
+
 func TestLoadMissingRequiredFields(t *testing.T) {
 	// Reset viper for clean test
 	viper.Reset()
@@ -245,6 +896,62 @@ func TestLoadMissingRequiredFields(t *testing.T) {
 	})
 }
 
+func TestResolveAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "sonarr")
+	if err := os.WriteFile(keyFile, []byte("file-key\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, nil, 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	t.Setenv("SCORECHECK_TEST_APIKEY", "env-key")
+
+	tests := []struct {
+		name          string
+		instance      map[string]any
+		expectedKey   string
+		expectedError string
+	}{
+		{name: "plain", instance: map[string]any{"apikey": "plain-key"}, expectedKey: "plain-key"},
+		{name: "file", instance: map[string]any{"apikey_file": keyFile}, expectedKey: "file-key"},
+		{name: "environment", instance: map[string]any{"apikey_env": "SCORECHECK_TEST_APIKEY"}, expectedKey: "env-key"},
+		{name: "shell command", instance: map[string]any{"apikey_command": "printf ' command-key\\n'"}, expectedKey: "command-key"},
+		{name: "command list", instance: map[string]any{"apikey_command": []any{"echo", "list-key"}}, expectedKey: "list-key"},
+		{name: "none", instance: map[string]any{}, expectedError: "missing apikey"},
+		{name: "more than one", instance: map[string]any{"apikey": "plain-key", "apikey_file": keyFile}, expectedError: "apikey, apikey_file"},
+		{name: "missing file", instance: map[string]any{"apikey_file": filepath.Join(dir, "missing")}, expectedError: "apikey_file"},
+		{name: "empty file", instance: map[string]any{"apikey_file": emptyFile}, expectedError: "empty API key"},
+		{name: "unset environment variable", instance: map[string]any{"apikey_env": "SCORECHECK_TEST_UNSET"}, expectedError: "SCORECHECK_TEST_UNSET"},
+		{name: "failing command", instance: map[string]any{"apikey_command": "echo locked >&2; exit 1"}, expectedError: "locked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := resolveAPIKey(tt.instance)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key != tt.expectedKey {
+				t.Errorf("expected key %q, got %q", tt.expectedKey, key)
+			}
+		})
+	}
+
+	// The output of a failing command may be a secret and is never shown
+	_, err := resolveAPIKey(map[string]any{"apikey_command": "echo secret-key; exit 1"})
+	if err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("expected error without the command output, got %v", err)
+	}
+}
+
 func TestLoadEmptyInstanceArrays(t *testing.T) {
 	// Reset viper for clean test
 	viper.Reset()
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// envPrefix is the prefix of every environment variable read by score-checker
+const envPrefix = "SCORECHECK"
+
+// envListSettings are instance settings given as comma separated lists in
+// environment variables
+var envListSettings = []string{"include_tags", "exclude_tags"}
+
+// envInstances reads the instances of a service defined by indexed
+// environment variables such as SCORECHECK_SONARR_0_BASEURL, keyed by index.
+// Setting names are lowercased to match the config file. BASIC_AUTH_USERNAME,
+// BASIC_AUTH_PASSWORD and HEADERS_<NAME> fill in the nested settings, with the
+// underscores in header names turned into dashes.
+func envInstances(key string, environ []string) (map[int]map[string]any, error) {
+	prefix := envPrefix + "_" + strings.ToUpper(key) + "_"
+	instances := make(map[int]map[string]any)
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		indexText, setting, ok := strings.Cut(rest, "_")
+		index, err := strconv.Atoi(indexText)
+		if err != nil || strings.ContainsAny(indexText, "+-") {
+			// Not an indexed instance setting
+			continue
+		}
+		if !ok || setting == "" {
+			return nil, fmt.Errorf("%s is missing a setting name after the index", name)
+		}
+
+		instance, ok := instances[index]
+		if !ok {
+			instance = make(map[string]any)
+			instances[index] = instance
+		}
+
+		setting = strings.ToLower(setting)
+		switch {
+		case strings.HasPrefix(setting, "basic_auth_"):
+			nestedSetting(instance, "basic_auth")[strings.TrimPrefix(setting, "basic_auth_")] = value
+		case strings.HasPrefix(setting, "headers_"):
+			header := strings.ReplaceAll(strings.TrimPrefix(setting, "headers_"), "_", "-")
+			nestedSetting(instance, "headers")[header] = value
+		case slices.Contains(envListSettings, setting):
+			var items []string
+			for item := range strings.SplitSeq(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			instance[setting] = items
+		default:
+			instance[setting] = value
+		}
+	}
+	return instances, nil
+}
+
+// nestedSetting returns the map held by a nested instance setting, creating it if needed
+func nestedSetting(instance map[string]any, key string) map[string]any {
+	nested, ok := instance[key].(map[string]any)
+	if !ok {
+		nested = make(map[string]any)
+		instance[key] = nested
+	}
+	return nested
+}
+
+// mergeEnvInstances applies instances from environment variables on top of
+// those from the config file. Settings from the environment replace the file's
+// settings of the instance with the same index; indexes right after the last
+// file instance add new instances.
+func mergeEnvInstances(key string, file []map[string]any, env map[int]map[string]any) ([]map[string]any, error) {
+	merged := slices.Clone(file)
+	for _, index := range slices.Sorted(maps.Keys(env)) {
+		settings := env[index]
+		switch {
+		case index < len(merged):
+			merged[index] = mergeSettings(merged[index], settings)
+		case index == len(merged):
+			merged = append(merged, settings)
+		default:
+			return nil, fmt.Errorf("%s_%s_%d_* is set, but there is no instance %d", envPrefix, strings.ToUpper(key), index, len(merged))
+		}
+	}
+	return merged, nil
+}
+
+// mergeSettings returns the settings of base overridden by those of override.
+// Nested settings are merged key by key, and an API key from override replaces
+// whichever way base gave one.
+func mergeSettings(base, override map[string]any) map[string]any {
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]any)
+	}
+	for _, source := range apiKeySources {
+		if override[source] != nil {
+			for _, other := range apiKeySources {
+				delete(merged, other)
+			}
+			break
+		}
+	}
+
+	for key, value := range override {
+		nested, isMap := value.(map[string]any)
+		if existing, ok := merged[key].(map[string]any); ok && isMap {
+			combined := maps.Clone(existing)
+			maps.Copy(combined, nested)
+			merged[key] = combined
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// loadEnvInstances merges the instances of a service defined in the
+// environment into those from the config file
+func loadEnvInstances(key string, file []map[string]any) ([]map[string]any, error) {
+	env, err := envInstances(key, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	return mergeEnvInstances(key, file, env)
+}
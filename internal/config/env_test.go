@@ -0,0 +1,148 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestEnvInstances(t *testing.T) {
+	environ := []string{
+		"SCORECHECK_SONARR_0_BASEURL=http://sonarr:8989",
+		"SCORECHECK_SONARR_0_APIKEY_FILE=/run/secrets/sonarr",
+		"SCORECHECK_SONARR_0_INCLUDE_TAGS=4k, hdr",
+		"SCORECHECK_SONARR_0_BASIC_AUTH_USERNAME=user",
+		"SCORECHECK_SONARR_0_HEADERS_CF_ACCESS_CLIENT_ID=client-id",
+		"SCORECHECK_SONARR_1_NAME=anime",
+		"SCORECHECK_SONARRTV_0_NAME=other",
+		"SCORECHECK_RADARR_0_NAME=movies",
+		"SCORECHECK_SONARR_BASEURL=http://ignored",
+		"PATH=/usr/bin",
+	}
+
+	instances, err := envInstances("sonarr", environ)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[int]map[string]any{
+		0: {
+			"baseurl":      "http://sonarr:8989",
+			"apikey_file":  "/run/secrets/sonarr",
+			"include_tags": []string{"4k", "hdr"},
+			"basic_auth":   map[string]any{"username": "user"},
+			"headers":      map[string]any{"cf-access-client-id": "client-id"},
+		},
+		1: {"name": "anime"},
+	}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("expected %v, got %v", expected, instances)
+	}
+
+	if _, err := envInstances("sonarr", []string{"SCORECHECK_SONARR_0=x"}); err == nil {
+		t.Error("expected error for a variable without a setting name")
+	}
+}
+
+func TestMergeEnvInstances(t *testing.T) {
+	file := []map[string]any{
+		{
+			"name":       "main",
+			"baseurl":    "http://localhost:8989",
+			"apikey":     "file-key",
+			"basic_auth": map[string]any{"username": "user", "password": "old"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		env         map[int]map[string]any
+		expected    []map[string]any
+		expectError bool
+	}{
+		{
+			name:     "no environment instances",
+			expected: file,
+		},
+		{
+			name: "override and add",
+			env: map[int]map[string]any{
+				0: {"baseurl": "http://sonarr:8989", "apikey_env": "SONARR_KEY", "basic_auth": map[string]any{"password": "new"}},
+				1: {"name": "anime", "baseurl": "http://anime:8989", "apikey": "anime-key"},
+			},
+			expected: []map[string]any{
+				{
+					"name":       "main",
+					"baseurl":    "http://sonarr:8989",
+					"apikey_env": "SONARR_KEY",
+					"basic_auth": map[string]any{"username": "user", "password": "new"},
+				},
+				{"name": "anime", "baseurl": "http://anime:8989", "apikey": "anime-key"},
+			},
+		},
+		{
+			name:        "gap in indexes",
+			env:         map[int]map[string]any{2: {"name": "gap"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeEnvInstances("sonarr", file, tt.env)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, merged)
+			}
+		})
+	}
+
+	// The config file's instances are left untouched
+	if file[0]["apikey"] != "file-key" || file[0]["basic_auth"].(map[string]any)["password"] != "old" {
+		t.Errorf("expected the file instances to be unchanged, got %v", file[0])
+	}
+}
+
+func TestLoadWithEnvInstances(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":    "main",
+			"baseurl": "http://localhost:7878",
+			"apikey":  "test-radarr-key",
+		},
+	})
+	t.Setenv("SCORECHECK_RADARR_0_THRESHOLD", "100")
+	t.Setenv("SCORECHECK_RADARR_1_NAME", "4k")
+	t.Setenv("SCORECHECK_RADARR_1_BASEURL", "http://localhost:7879")
+	t.Setenv("SCORECHECK_RADARR_1_APIKEY", "test-radarr-4k-key")
+	t.Setenv("SCORECHECK_SONARR_0_BASEURL", "http://localhost:8989")
+	t.Setenv("SCORECHECK_SONARR_0_APIKEY", "test-sonarr-key")
+
+	cfg := Load()
+
+	if len(cfg.RadarrInstances) != 2 {
+		t.Fatalf("expected 2 Radarr instances, got %d", len(cfg.RadarrInstances))
+	}
+	if main := cfg.RadarrInstances[0]; main.Name != "main" || main.Threshold != 100 {
+		t.Errorf("expected 'main' instance with threshold 100 from the environment, got %+v", main)
+	}
+	if fourK := cfg.RadarrInstances[1]; fourK.Name != "4k" || fourK.BaseURL != "http://localhost:7879" || fourK.APIKey != "test-radarr-4k-key" {
+		t.Errorf("expected '4k' instance from the environment, got %+v", fourK)
+	}
+	if len(cfg.SonarrInstances) != 1 || cfg.SonarrInstances[0].Name != "default" {
+		t.Errorf("expected a 'default' Sonarr instance from the environment, got %+v", cfg.SonarrInstances)
+	}
+}
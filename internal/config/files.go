@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// configEnv names the environment variable pointing at the config file or
+// directory, used when --config isn't given
+const configEnv = envPrefix + "_CONFIG"
+
+// configPaths are the default locations of the config file and conf.d directory
+var configPaths = []string{".", "/etc/score-checker/"}
+
+// configExts are the config file formats, in the order a config file is
+// looked for in the default locations
+var configExts = []string{"yaml", "yml", "json", "toml"}
+
+var (
+	// configPath is the config file or directory given explicitly, "" to use
+	// the default locations
+	configPath string
+	// configFile is the main config file read last, "" if there is none
+	configFile string
+	// configDir is the conf.d directory read last, "" if there is none
+	configDir string
+	// configFiles are every file read last, in the order they were merged
+	configFiles []string
+	// configErr is why an explicitly given config couldn't be read at
+	// startup, reported as a configuration error
+	configErr error
+)
+
+// errNoConfig is returned when there is no config file to read
+var errNoConfig = errors.New("no config file found")
+
+// locateConfig finds the main config file and the conf.d directory. An
+// explicit path is either a config file, with an optional conf.d directory
+// next to it, or a directory whose files are all merged.
+func locateConfig(path string) (file, dir string, err error) {
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", "", err
+		}
+		if info.IsDir() {
+			return "", path, nil
+		}
+		return path, findConfigDir([]string{filepath.Dir(path)}), nil
+	}
+
+	for _, location := range configPaths {
+		for _, ext := range configExts {
+			candidate := filepath.Join(location, "config."+ext)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, findConfigDir([]string{location}), nil
+			}
+		}
+	}
+	return "", findConfigDir(configPaths), nil
+}
+
+// findConfigDir returns the first conf.d directory in locations, "" if there is none
+func findConfigDir(locations []string) string {
+	for _, location := range locations {
+		dir := filepath.Join(location, "conf.d")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// configDirFiles lists the config files in dir in lexical order, skipping
+// hidden files such as editor swap files and Kubernetes' ..data links
+func configDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		if strings.HasPrefix(name, ".") || !slices.Contains(configExts, ext) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// readSettings reads a config file on its own, with its format taken from
+// its extension
+func readSettings(file string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// readConfig reads the main config file and merges the files of the conf.d
+// directory into it in lexical order. Settings from later files replace those
+// of earlier ones, except for instance lists, which are appended. Without a
+// main config file, the first file of the directory takes its place.
+func readConfig() error {
+	file, dir, err := locateConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	if file != "" {
+		files = append(files, file)
+	}
+	if dir != "" {
+		dirFiles, err := configDirFiles(dir)
+		if err != nil {
+			return fmt.Errorf("reading config directory: %w", err)
+		}
+		files = append(files, dirFiles...)
+	}
+	if len(files) == 0 {
+		if dir != "" {
+			return fmt.Errorf("%w in %s", errNoConfig, dir)
+		}
+		return errNoConfig
+	}
+
+	// Read every file before touching the configuration in use, so a broken
+	// file doesn't leave it half updated
+	instances := make(map[string][]any)
+	fragments := make([]map[string]any, 0, len(files)-1)
+	for i, f := range files {
+		settings, err := readSettings(f)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f, err)
+		}
+		for _, service := range services {
+			list, ok := settings[service.key]
+			if !ok {
+				continue
+			}
+			items, err := cast.ToSliceE(list)
+			if err != nil {
+				return fmt.Errorf("reading %s: %s must be a list of instances", f, service.key)
+			}
+			instances[service.key] = append(instances[service.key], items...)
+			delete(settings, service.key)
+		}
+		if i > 0 {
+			fragments = append(fragments, settings)
+		}
+	}
+
+	viper.SetConfigFile(files[0])
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("reading %s: %w", files[0], err)
+	}
+	for _, settings := range fragments {
+		if err := viper.MergeConfigMap(settings); err != nil {
+			return fmt.Errorf("merging config files: %w", err)
+		}
+	}
+	if len(files) > 1 {
+		combined := make(map[string]any, len(instances))
+		for key, items := range instances {
+			combined[key] = items
+		}
+		if err := viper.MergeConfigMap(combined); err != nil {
+			return fmt.Errorf("merging config files: %w", err)
+		}
+	}
+
+	configFile, configDir, configFiles = file, dir, files
+	return nil
+}
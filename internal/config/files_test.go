@@ -0,0 +1,239 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writeFiles writes config files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// instanceNames returns the names of the instances of a service in the
+// current configuration
+func instanceNames(t *testing.T, key string) []string {
+	t.Helper()
+	var instances []map[string]any
+	if err := viper.UnmarshalKey(key, &instances); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", key, err)
+	}
+	var names []string
+	for _, instance := range instances {
+		name, _ := instance["name"].(string)
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestInitWithConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+batchsize: 3
+threshold: 10
+exclude:
+  titles: ["The Office"]
+sonarr:
+  - name: main
+    baseurl: http://localhost:8989
+    apikey: main-key
+`,
+		"conf.d/20-movies.json": `{
+  "threshold": 20,
+  "radarr": [{"name": "movies", "baseurl": "http://localhost:7878", "apikey": "movies-key"}]
+}`,
+		"conf.d/10-anime.toml": `
+batchsize = 8
+
+[exclude]
+ids = [42]
+
+[[sonarr]]
+name = "anime"
+baseurl = "http://localhost:8990"
+apikey = "anime-key"
+`,
+		"conf.d/.10-anime.toml.swp": "not a config file",
+		"conf.d/README.md":          "not a config file",
+	})
+
+	// Reset viper for clean test
+	viper.Reset()
+	InitWithConfig(filepath.Join(dir, "config.yaml"))
+
+	expectedFiles := []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "conf.d", "10-anime.toml"),
+		filepath.Join(dir, "conf.d", "20-movies.json"),
+	}
+	if !slices.Equal(configFiles, expectedFiles) {
+		t.Errorf("expected files %v, got %v", expectedFiles, configFiles)
+	}
+
+	cfg := Load()
+	if cfg.BatchSize != 8 || cfg.Threshold != 20 {
+		t.Errorf("expected later files to override settings, got batch size %d and threshold %d", cfg.BatchSize, cfg.Threshold)
+	}
+	if len(cfg.Exclude.Titles) != 1 || len(cfg.Exclude.IDs) != 1 {
+		t.Errorf("expected nested settings to be merged, got %+v", cfg.Exclude)
+	}
+	if names := instanceNames(t, "sonarr"); !slices.Equal(names, []string{"main", "anime"}) {
+		t.Errorf("expected Sonarr instances to be appended in file order, got %v", names)
+	}
+	if len(cfg.SonarrInstances) != 2 || cfg.SonarrInstances[1].APIKey != "anime-key" {
+		t.Errorf("expected the 'anime' instance from conf.d, got %+v", cfg.SonarrInstances)
+	}
+	if len(cfg.RadarrInstances) != 1 || cfg.RadarrInstances[0].Name != "movies" {
+		t.Errorf("expected the 'movies' instance from conf.d, got %+v", cfg.RadarrInstances)
+	}
+	if logDir := determineLogDir(); logDir != dir {
+		t.Errorf("expected log directory %s, got %s", dir, logDir)
+	}
+}
+
+func TestInitWithConfigSources(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		path          string // relative to the test directory
+		env           string // relative to the test directory
+		expectedBatch int
+		expectError   bool
+	}{
+		{
+			name:          "default location",
+			files:         map[string]string{"config.toml": "batchsize = 2\n", "conf.d/team.yaml": "threshold: 5\n"},
+			expectedBatch: 2,
+		},
+		{
+			name:          "explicit JSON file",
+			files:         map[string]string{"config.yaml": "batchsize: 2\n", "other.json": `{"batchsize": 4}`},
+			path:          "other.json",
+			expectedBatch: 4,
+		},
+		{
+			name:          "environment variable",
+			files:         map[string]string{"custom.yml": "batchsize: 6\n"},
+			env:           "custom.yml",
+			expectedBatch: 6,
+		},
+		{
+			name:          "flag takes precedence over environment variable",
+			files:         map[string]string{"flag.yaml": "batchsize: 7\n", "env.yaml": "batchsize: 9\n"},
+			path:          "flag.yaml",
+			env:           "env.yaml",
+			expectedBatch: 7,
+		},
+		{
+			name:          "directory only",
+			files:         map[string]string{"teams/a.yaml": "batchsize: 1\n", "teams/b.yaml": "batchsize: 11\n"},
+			path:          "teams",
+			expectedBatch: 11,
+		},
+		{
+			name:        "missing file",
+			path:        "missing.yaml",
+			expectError: true,
+		},
+		{
+			name:        "empty directory",
+			files:       map[string]string{"empty/README.md": "no config here"},
+			path:        "empty",
+			expectError: true,
+		},
+		{
+			name:        "invalid conf.d file",
+			files:       map[string]string{"config.yaml": "batchsize: 2\n", "conf.d/broken.json": "{"},
+			expectError: true,
+		},
+		{
+			name:          "no config file",
+			expectedBatch: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			t.Chdir(dir)
+			t.Setenv(configEnv, "")
+			if tt.env != "" {
+				t.Setenv(configEnv, filepath.Join(dir, tt.env))
+			}
+			path := ""
+			if tt.path != "" {
+				path = filepath.Join(dir, tt.path)
+			}
+
+			// Reset viper for clean test
+			viper.Reset()
+			InitWithConfig(path)
+
+			cfg, errs := build()
+			if tt.expectError {
+				if len(errs) == 0 {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if cfg.BatchSize != tt.expectedBatch {
+				t.Errorf("expected batch size %d, got %d", tt.expectedBatch, cfg.BatchSize)
+			}
+			// Log and state files never go in a directory of config files
+			if logDir := determineLogDir(); logDir == filepath.Join(dir, "teams") {
+				t.Errorf("expected the log directory to be outside the config directory, got %s", logDir)
+			}
+		})
+	}
+}
+
+func TestReloadConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yaml": "sonarr:\n  - name: a\n    baseurl: http://localhost:8989\n    apikey: a-key\n",
+		"b.yaml": "sonarr:\n  - name: b\n    baseurl: http://localhost:8990\n    apikey: b-key\n",
+	})
+
+	// Reset viper for clean test
+	viper.Reset()
+	InitWithConfig(dir)
+
+	// Instances aren't appended again on every reload
+	for range 2 {
+		if _, err := reload(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if names := instanceNames(t, "sonarr"); !slices.Equal(names, []string{"a", "b"}) {
+		t.Errorf("expected instances a and b, got %v", names)
+	}
+
+	// A removed file takes its instances with it
+	if err := os.Remove(filepath.Join(dir, "b.yaml")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if _, err := reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := instanceNames(t, "sonarr"); !slices.Equal(names, []string{"a"}) {
+		t.Errorf("expected instance a, got %v", names)
+	}
+}
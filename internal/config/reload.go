@@ -0,0 +1,136 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"score-checker/internal/types"
+)
+
+// reloadDelay is how long the config file has to stay unchanged before it's
+// reloaded, as editors often write a file in several steps
+const reloadDelay = 250 * time.Millisecond
+
+// Watch reloads the configuration whenever the config file or the files in
+// the conf.d directory change, or the process receives SIGHUP, until ctx is
+// canceled. Each new configuration that is valid is sent on the returned
+// channel, which only ever holds the latest one. Invalid configurations are
+// logged and skipped, so whatever was loaded last stays in use.
+func Watch(ctx context.Context) <-chan types.Config {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		<-ctx.Done()
+		signal.Stop(hup)
+	}()
+
+	return watch(ctx, configFile, configDir, hup)
+}
+
+// watch reloads the configuration when the file at path or anything in dir
+// changes, or hup receives a value. With neither only hup triggers reloads.
+func watch(ctx context.Context, path, dir string, hup <-chan os.Signal) <-chan types.Config {
+	configs := make(chan types.Config, 1)
+
+	// Watch the directory rather than the file, so the file is still
+	// followed after an editor or a mounted Kubernetes ConfigMap replaces it
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	var watcher *fsnotify.Watcher
+	if path != "" || dir != "" {
+		var err error
+		watcher, err = fsnotify.NewWatcher()
+		if err == nil && path != "" {
+			err = watcher.Add(filepath.Dir(path))
+		}
+		if err == nil && dir != "" {
+			err = watcher.Add(dir)
+		}
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to watch config file, only SIGHUP reloads it: %v", err))
+		} else {
+			events, watchErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	go func() {
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		realPath, _ := filepath.EvalSymlinks(path)
+		delay := time.NewTimer(reloadDelay)
+		delay.Stop()
+		defer delay.Stop()
+
+		apply := func(reason string) {
+			slog.Info(fmt.Sprintf("%s, reloading configuration", reason))
+			config, err := reload()
+			if err != nil {
+				slog.Error(fmt.Sprintf("Invalid configuration, keeping the current one:\n%v", err))
+				return
+			}
+			// Replace a configuration that hasn't been picked up yet
+			select {
+			case <-configs:
+			default:
+			}
+			configs <- config
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-hup:
+				apply("Received SIGHUP")
+
+			case event, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				current, _ := filepath.EvalSymlinks(path)
+				changed := filepath.Clean(event.Name) == filepath.Clean(path) && event.Has(fsnotify.Write|fsnotify.Create)
+				inDir := dir != "" && filepath.Dir(event.Name) == filepath.Clean(dir)
+				if changed || inDir || (current != "" && current != realPath) {
+					realPath = current
+					delay.Reset(reloadDelay)
+				}
+
+			case err, ok := <-watchErrors:
+				if !ok {
+					watchErrors = nil
+					continue
+				}
+				slog.Error(fmt.Sprintf("Error watching config file: %v", err))
+
+			case <-delay.C:
+				apply("Config file changed")
+			}
+		}
+	}()
+
+	return configs
+}
+
+// reload reads the config files again and validates the configuration. The
+// files read last are kept if the new ones can't be read.
+func reload() (types.Config, error) {
+	if err := readConfig(); err != nil && !(errors.Is(err, errNoConfig) && configPath == "") {
+		return types.Config{}, fmt.Errorf("reading config: %w", err)
+	}
+	configErr = nil
+
+	return check()
+}
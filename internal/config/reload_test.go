@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"score-checker/internal/types"
+)
+
+// writeConfig writes a config file
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+// nextConfig waits for a reloaded configuration
+func nextConfig(t *testing.T, configs <-chan types.Config) types.Config {
+	t.Helper()
+	select {
+	case config := <-configs:
+		return config
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the configuration to be reloaded")
+		return types.Config{}
+	}
+}
+
+func TestReload(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configFile, "batchsize: 3\n")
+	InitWithConfig(configFile)
+
+	writeConfig(t, configFile, "batchsize: 7\ninterval: 2h\n")
+	config, err := reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.BatchSize != 7 || config.Interval != 2*time.Hour {
+		t.Errorf("expected batch size 7 and interval 2h, got %d and %v", config.BatchSize, config.Interval)
+	}
+
+	for name, content := range map[string]string{
+		"invalid yaml":      "batchsize: [\n",
+		"invalid mode":      "mode: best\n",
+		"negative interval": "interval: -1h\n",
+		"duplicate names": `
+sonarr:
+  - name: main
+    baseurl: http://localhost:8989
+    apikey: key
+  - name: main
+    baseurl: http://localhost:8990
+    apikey: key
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeConfig(t, configFile, content)
+			if _, err := reload(); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configFile, "batchsize: 3\n")
+	InitWithConfig(configFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hup := make(chan os.Signal, 1)
+	configs := watch(ctx, configFile, "", hup)
+
+	// A change to the file is picked up
+	writeConfig(t, configFile, "batchsize: 7\n")
+	if config := nextConfig(t, configs); config.BatchSize != 7 {
+		t.Errorf("expected batch size 7, got %d", config.BatchSize)
+	}
+
+	// An invalid file is skipped
+	writeConfig(t, configFile, "mode: best\n")
+	select {
+	case config := <-configs:
+		t.Errorf("expected the invalid configuration to be skipped, got %+v", config)
+	case <-time.After(4 * reloadDelay):
+	}
+
+	// A file replaced by another one, as some editors save, is picked up too
+	replacement := configFile + ".tmp"
+	writeConfig(t, replacement, "batchsize: 9\n")
+	if err := os.Rename(replacement, configFile); err != nil {
+		t.Fatalf("failed to replace config: %v", err)
+	}
+	if config := nextConfig(t, configs); config.BatchSize != 9 {
+		t.Errorf("expected batch size 9, got %d", config.BatchSize)
+	}
+
+	// SIGHUP reloads right away
+	viper.Set("batchsize", 11)
+	hup <- os.Interrupt
+	if config := nextConfig(t, configs); config.BatchSize != 11 {
+		t.Errorf("expected batch size 11, got %d", config.BatchSize)
+	}
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"score-checker/internal/types"
+)
+
+// Where a setting's value came from
+const (
+	sourceFlag      = "flag"
+	sourceEnv       = "env"
+	sourceFile      = "file"
+	sourceDefault   = "default"
+	sourceInherited = "inherited"
+)
+
+// globalSetting renders a top-level setting of the resolved configuration
+type globalSetting struct {
+	key   string
+	value func(c types.Config) string
+}
+
+var globalSettings = []globalSetting{
+	{"triggersearch", func(c types.Config) string { return strconv.FormatBool(c.TriggerSearch) }},
+	{"batchsize", func(c types.Config) string { return strconv.Itoa(c.BatchSize) }},
+	{"interval", func(c types.Config) string { return c.Interval.String() }},
+	{"loglevel", func(c types.Config) string { return c.LogLevel }},
+	{"threshold", func(c types.Config) string { return strconv.Itoa(c.Threshold) }},
+	{"mode", func(c types.Config) string { return c.Mode }},
+	{"cooldown", func(c types.Config) string { return c.Cooldown.String() }},
+	{"order", func(c types.Config) string { return c.Order }},
+	{"monitoredonly", func(c types.Config) string { return strconv.FormatBool(c.MonitoredOnly) }},
+	{"exclude", func(c types.Config) string { return formatExclude(c.Exclude) }},
+	{"concurrency", func(c types.Config) string { return strconv.Itoa(c.Concurrency) }},
+	{"workers", func(c types.Config) string { return strconv.Itoa(c.Workers) }},
+	{"retryattempts", func(c types.Config) string { return strconv.Itoa(c.Retry.Attempts) }},
+	{"retrydelay", func(c types.Config) string { return c.Retry.Delay.String() }},
+	{"retrymaxdelay", func(c types.Config) string { return c.Retry.MaxDelay.String() }},
+	{"ratelimit", func(c types.Config) string { return formatFloat(c.RateLimit.RequestsPerSecond) }},
+	{"rateburst", func(c types.Config) string { return strconv.Itoa(c.RateLimit.Burst) }},
+	{"timeout", func(c types.Config) string { return c.Timeout.String() }},
+	{"graceperiod", func(c types.Config) string { return c.GracePeriod.String() }},
+}
+
+// instanceSetting renders a setting of a resolved instance. raw is the
+// instance's entry, for settings that only exist there such as file paths.
+type instanceSetting struct {
+	key   string
+	value func(c types.ServiceConfig, raw map[string]any) string
+}
+
+var instanceSettings = []instanceSetting{
+	{"name", func(c types.ServiceConfig, _ map[string]any) string { return c.Name }},
+	{"baseurl", func(c types.ServiceConfig, _ map[string]any) string { return c.BaseURL }},
+	{"apikey", func(c types.ServiceConfig, _ map[string]any) string { return mask(c.APIKey) }},
+	{"apikey_in_query", func(c types.ServiceConfig, _ map[string]any) string { return strconv.FormatBool(c.APIKeyInQuery) }},
+	{"threshold", func(c types.ServiceConfig, _ map[string]any) string { return strconv.Itoa(c.Threshold) }},
+	{"mode", func(c types.ServiceConfig, _ map[string]any) string { return c.Mode }},
+	{"cooldown", func(c types.ServiceConfig, _ map[string]any) string { return c.Cooldown.String() }},
+	{"order", func(c types.ServiceConfig, _ map[string]any) string { return c.Order }},
+	{"monitoredonly", func(c types.ServiceConfig, _ map[string]any) string { return strconv.FormatBool(c.MonitoredOnly) }},
+	{"exclude", func(c types.ServiceConfig, _ map[string]any) string { return formatExclude(c.Exclude) }},
+	{"include_tags", func(c types.ServiceConfig, _ map[string]any) string { return formatList(c.IncludeTags) }},
+	{"exclude_tags", func(c types.ServiceConfig, _ map[string]any) string { return formatList(c.ExcludeTags) }},
+	{"workers", func(c types.ServiceConfig, _ map[string]any) string { return strconv.Itoa(c.Workers) }},
+	{"retryattempts", func(c types.ServiceConfig, _ map[string]any) string { return strconv.Itoa(c.Retry.Attempts) }},
+	{"retrydelay", func(c types.ServiceConfig, _ map[string]any) string { return c.Retry.Delay.String() }},
+	{"retrymaxdelay", func(c types.ServiceConfig, _ map[string]any) string { return c.Retry.MaxDelay.String() }},
+	{"ratelimit", func(c types.ServiceConfig, _ map[string]any) string {
+		return formatFloat(c.RateLimit.RequestsPerSecond)
+	}},
+	{"rateburst", func(c types.ServiceConfig, _ map[string]any) string { return strconv.Itoa(c.RateLimit.Burst) }},
+	{"timeout", func(c types.ServiceConfig, _ map[string]any) string { return c.Transport.Timeout.String() }},
+	{"ca_file", func(_ types.ServiceConfig, raw map[string]any) string { return cast.ToString(raw["ca_file"]) }},
+	{"client_cert", func(_ types.ServiceConfig, raw map[string]any) string { return cast.ToString(raw["client_cert"]) }},
+	{"client_key", func(_ types.ServiceConfig, raw map[string]any) string { return cast.ToString(raw["client_key"]) }},
+	{"insecure_skip_verify", func(c types.ServiceConfig, _ map[string]any) string {
+		return strconv.FormatBool(c.Transport.TLS != nil && c.Transport.TLS.InsecureSkipVerify)
+	}},
+	{"proxy", func(c types.ServiceConfig, _ map[string]any) string {
+		if c.Transport.Proxy == nil {
+			return ""
+		}
+		return c.Transport.Proxy.Redacted()
+	}},
+	{"basic_auth", func(c types.ServiceConfig, _ map[string]any) string {
+		if c.BasicAuth == nil {
+			return ""
+		}
+		return c.BasicAuth.Username + ":" + mask(c.BasicAuth.Password)
+	}},
+	{"headers", func(c types.ServiceConfig, _ map[string]any) string {
+		var headers []string
+		for _, name := range slices.Sorted(maps.Keys(c.Headers)) {
+			headers = append(headers, name+": "+mask(c.Headers[name]))
+		}
+		return strings.Join(headers, ", ")
+	}},
+}
+
+// Show writes the resolved configuration to w with secrets masked, along with
+// where each value came from: a command line flag, an environment variable,
+// the config file, a default, or for instances, inherited from the general
+// settings. flags are the command line flags bound to the settings.
+func Show(w io.Writer, flags *pflag.FlagSet) error {
+	config, errs := build()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	files := configFiles
+	if len(files) == 0 && viper.ConfigFileUsed() != "" {
+		files = []string{viper.ConfigFileUsed()}
+	}
+	switch len(files) {
+	case 0:
+		fmt.Fprintln(tw, "Config file: none")
+	case 1:
+		fmt.Fprintf(tw, "Config file: %s\n", files[0])
+	default:
+		fmt.Fprintln(tw, "Config files, merged in this order:")
+		for _, file := range files {
+			fmt.Fprintf(tw, "  %s\n", file)
+		}
+	}
+
+	fmt.Fprintln(tw, "\nGeneral settings:")
+	for _, setting := range globalSettings {
+		fmt.Fprintf(tw, "  %s\t%s\t(%s)\n", setting.key, setting.value(config), globalSource(setting.key, flags))
+	}
+
+	instances := map[string][]types.ServiceConfig{
+		"sonarr":  config.SonarrInstances,
+		"radarr":  config.RadarrInstances,
+		"lidarr":  config.LidarrInstances,
+		"readarr": config.ReadarrInstances,
+	}
+	for _, service := range services {
+		var file []map[string]any
+		_ = viper.UnmarshalKey(service.key, &file)
+		env, err := envInstances(service.key, os.Environ())
+		if err != nil {
+			return err
+		}
+		raw, err := mergeEnvInstances(service.key, file, env)
+		if err != nil {
+			return err
+		}
+
+		for i, instance := range instances[service.key] {
+			fmt.Fprintf(tw, "\n%s instance '%s':\n", service.name, instance.Name)
+			for _, setting := range instanceSettings {
+				value := setting.value(instance, raw[i])
+				source := instanceSource(setting.key, i, file, env)
+				if source != sourceEnv && source != sourceFile && value == "" {
+					continue
+				}
+				fmt.Fprintf(tw, "  %s\t%s\t(%s)\n", setting.key, value, source)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// globalSource returns where a top-level setting's value came from, in the
+// order Viper looks for it
+func globalSource(key string, flags *pflag.FlagSet) string {
+	if flags != nil && flags.Changed(key) {
+		return sourceFlag
+	}
+	if _, ok := os.LookupEnv(envPrefix + "_" + strings.ToUpper(key)); ok {
+		return sourceEnv
+	}
+	if viper.InConfig(key) {
+		return sourceFile
+	}
+	return sourceDefault
+}
+
+// instanceSource returns where an instance setting's value came from. The
+// API key names the setting it was read with.
+func instanceSource(key string, index int, file []map[string]any, env map[int]map[string]any) string {
+	keys := []string{key}
+	if key == "apikey" {
+		keys = apiKeySources
+	}
+	for _, k := range keys {
+		label := sourceEnv
+		if k != key {
+			label += ", " + k
+		}
+		if _, ok := env[index][k]; ok {
+			return label
+		}
+	}
+	for _, k := range keys {
+		label := sourceFile
+		if k != key {
+			label += ", " + k
+		}
+		if index < len(file) {
+			if _, ok := file[index][k]; ok {
+				return label
+			}
+		}
+	}
+	// Only settings that also exist at the top level are inherited
+	if slices.Contains(globalKeys, key) {
+		return sourceInherited
+	}
+	return sourceDefault
+}
+
+// mask hides a secret, keeping the last few characters of long ones so
+// different keys can still be told apart
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < 16 {
+		return "********"
+	}
+	return "********" + secret[len(secret)-4:]
+}
+
+func formatList(items []string) string {
+	return strings.Join(items, ", ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatExclude summarizes the exclusions of a configuration
+func formatExclude(exclude types.ExcludeConfig) string {
+	var parts []string
+	add := func(name string, n int) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, name))
+		}
+	}
+	add("title(s)", len(exclude.Titles))
+	add("pattern(s)", len(exclude.Patterns))
+	add("ID(s)", len(exclude.IDs))
+	add("TVDB ID(s)", len(exclude.TvdbIDs))
+	add("TMDB ID(s)", len(exclude.TmdbIDs))
+	add("IMDb ID(s)", len(exclude.ImdbIDs))
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestShow(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+batchsize: 10
+sonarr:
+  - name: "main"
+    baseurl: "http://localhost:8989"
+    apikey: "0123456789abcdef0123456789abcdef"
+    basic_auth:
+      username: "user"
+      password: "hunter2"
+    headers:
+      CF-Access-Client-Secret: "cf-secret"
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("threshold", 0, "")
+	_ = viper.BindPFlag("threshold", flags.Lookup("threshold"))
+	if err := flags.Parse([]string{"--threshold", "50"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	t.Setenv("SCORECHECK_INTERVAL", "2h")
+	t.Setenv("SCORECHECK_SONARR_0_WORKERS", "2")
+
+	var buf bytes.Buffer
+	if err := Show(&buf, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	expected := []string{
+		`batchsize\s+10\s+\(file\)`,
+		`interval\s+2h0m0s\s+\(env\)`,
+		`threshold\s+50\s+\(flag\)`,
+		`cooldown\s+0s\s+\(default\)`,
+		`Sonarr instance 'main':`,
+		`apikey\s+\*+cdef\s+\(file\)`,
+		`workers\s+2\s+\(env\)`,
+		`threshold\s+50\s+\(inherited\)`,
+		`basic_auth\s+user:\*+\s+\(file\)`,
+	}
+	for _, pattern := range expected {
+		if !regexp.MustCompile(pattern).MatchString(output) {
+			t.Errorf("expected output to match %q, got:\n%s", pattern, output)
+		}
+	}
+	for _, secret := range []string{"0123456789abcdef0123456789abcdef", "hunter2", "cf-secret"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected %q to be masked, got:\n%s", secret, output)
+		}
+	}
+}
+
+func TestShowInvalidConfig(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("sonarr", []map[string]interface{}{{"name": "main"}})
+
+	if err := Show(&bytes.Buffer{}, nil); err == nil {
+		t.Error("expected error for an invalid configuration")
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		secret   string
+		expected string
+	}{
+		{secret: "", expected: ""},
+		{secret: "short", expected: "********"},
+		{secret: "0123456789abcdef0123456789abcdef", expected: "********cdef"},
+	}
+
+	for _, tt := range tests {
+		if got := mask(tt.secret); got != tt.expected {
+			t.Errorf("mask(%q): expected %q, got %q", tt.secret, tt.expected, got)
+		}
+	}
+}
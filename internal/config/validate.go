@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+
+	"score-checker/internal/types"
+)
+
+// globalKeys are the settings allowed at the top level of the config file
+var globalKeys = []string{
+	"triggersearch", "batchsize", "interval", "loglevel", "threshold", "mode", "cooldown", "order",
+	"monitoredonly", "exclude", "concurrency", "workers", "retryattempts", "retrydelay",
+	"retrymaxdelay", "ratelimit", "rateburst", "timeout", "graceperiod",
+	"sonarr", "radarr", "lidarr", "readarr",
+}
+
+// instanceKeys are the settings allowed in an instance entry
+var instanceKeys = []string{
+	"name", "baseurl", "apikey", "apikey_file", "apikey_env", "apikey_command", "threshold", "mode",
+	"cooldown", "order", "monitoredonly", "exclude", "include_tags", "exclude_tags", "workers",
+	"retryattempts", "retrydelay", "retrymaxdelay", "ratelimit", "rateburst", "timeout", "ca_file",
+	"client_cert", "client_key", "insecure_skip_verify", "proxy", "basic_auth", "headers",
+	"apikey_in_query",
+}
+
+// logLevels are the supported log levels
+var logLevels = []string{"ERROR", "INFO", "DEBUG", "VERBOSE"}
+
+// services lists the config key and display name of every supported service
+var services = []struct{ key, name string }{
+	{"sonarr", "Sonarr"},
+	{"radarr", "Radarr"},
+	{"lidarr", "Lidarr"},
+	{"readarr", "Readarr"},
+}
+
+// Report lists the problems found in the configuration. Errors stop
+// score-checker from starting, warnings are likely mistakes.
+type Report struct {
+	Errors   []string
+	Warnings []string
+}
+
+func (r *Report) errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the whole configuration, reporting likely mistakes as
+// warnings along with the errors Load refuses to start with
+func Validate() Report {
+	_, report := validate()
+	return report
+}
+
+// check builds the configuration and returns every error validate reports,
+// so startup and reloads reject the same configurations as config validate
+func check() (types.Config, error) {
+	config, report := validate()
+	if len(report.Errors) > 0 {
+		errs := make([]error, len(report.Errors))
+		for i, message := range report.Errors {
+			errs[i] = errors.New(message)
+		}
+		return types.Config{}, errors.Join(errs...)
+	}
+	return config, nil
+}
+
+// validate builds the configuration and checks it for problems
+func validate() (types.Config, Report) {
+	var report Report
+
+	config, errs := build()
+	for _, err := range errs {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	if viper.GetString("interval") != "" && config.Interval <= 0 {
+		report.errorf("Invalid interval: must be positive, got %s", viper.GetString("interval"))
+	}
+	switch {
+	case config.BatchSize < 0:
+		report.errorf("Invalid batch size: must not be negative, got %d", config.BatchSize)
+	case config.BatchSize == 0:
+		report.warnf("Batch size is 0, every low score item is searched on each run")
+	}
+	if !slices.Contains(logLevels, strings.ToUpper(config.LogLevel)) {
+		report.warnf("Unknown log level %q (expected %s)", config.LogLevel, strings.Join(logLevels, ", "))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(viper.AllSettings())) {
+		if !slices.Contains(globalKeys, key) {
+			report.warnf("Unknown key '%s'", key)
+		}
+	}
+
+	for _, service := range services {
+		instances, err := rawServiceInstances(service.key, service.name)
+		if err != nil {
+			// Already reported by build
+			continue
+		}
+		validateInstances(&report, service.name, instances)
+	}
+
+	return config, report
+}
+
+// validateInstances checks the raw entries of a service's instances for
+// problems that parsing them doesn't catch
+func validateInstances(report *Report, serviceName string, instances []map[string]any) {
+	seen := make(map[string]bool)
+	for i, instance := range instances {
+		name, ok := instance["name"].(string)
+		if !ok {
+			name = generateInstanceName(i)
+		}
+		if seen[name] {
+			report.errorf("%s instance '%s' is defined more than once", serviceName, name)
+		}
+		seen[name] = true
+
+		for _, key := range slices.Sorted(maps.Keys(instance)) {
+			if !slices.Contains(instanceKeys, key) {
+				report.warnf("%s instance '%s' has unknown key '%s'", serviceName, name, key)
+			}
+		}
+
+		if baseURL, ok := instance["baseurl"].(string); ok {
+			if err := checkBaseURL(baseURL); err != nil {
+				report.errorf("%s instance '%s' has malformed baseurl %q: %v", serviceName, name, baseURL, err)
+			} else if strings.HasSuffix(baseURL, "/") {
+				report.warnf("%s instance '%s' baseurl ends with a slash, API paths will contain '//'", serviceName, name)
+			}
+		}
+
+		if insecure, err := cast.ToBoolE(instance["insecure_skip_verify"]); err == nil && insecure {
+			report.warnf("%s instance '%s' doesn't verify the server's TLS certificate", serviceName, name)
+		}
+	}
+}
+
+// checkBaseURL reports whether baseURL is an absolute http or https URL
+func checkBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("must not have a query or fragment")
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidate(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("batchsize", 0)
+	viper.Set("mode", "best")
+	viper.Set("intervall", "2h")
+	viper.Set("sonarr", []map[string]interface{}{
+		{
+			"name":     "main",
+			"baseurl":  "http://localhost:8989/",
+			"apikey":   "test-sonarr-key",
+			"treshold": 100,
+		},
+		{
+			"name":    "main",
+			"baseurl": "localhost:8989",
+			"apikey":  "test-sonarr-key",
+		},
+	})
+	viper.Set("radarr", []map[string]interface{}{
+		{
+			"name":     "main",
+			"baseurl":  "http://localhost:7878",
+			"cooldown": "soon",
+		},
+	})
+
+	report := Validate()
+
+	expectedErrors := []string{
+		"Invalid mode",
+		"Sonarr instance 'main' is defined more than once",
+		`Sonarr instance 'main' has malformed baseurl "localhost:8989"`,
+		"Radarr instance 'main' missing apikey",
+		"Radarr instance 'main' has invalid cooldown",
+	}
+	expectedWarnings := []string{
+		"Batch size is 0",
+		"Unknown key 'intervall'",
+		"Sonarr instance 'main' has unknown key 'treshold'",
+		"Sonarr instance 'main' baseurl ends with a slash",
+	}
+	for _, expected := range expectedErrors {
+		if !slices.ContainsFunc(report.Errors, func(e string) bool { return strings.Contains(e, expected) }) {
+			t.Errorf("expected an error containing %q, got %q", expected, report.Errors)
+		}
+	}
+	for _, expected := range expectedWarnings {
+		if !slices.ContainsFunc(report.Warnings, func(w string) bool { return strings.Contains(w, expected) }) {
+			t.Errorf("expected a warning containing %q, got %q", expected, report.Warnings)
+		}
+	}
+	if len(report.Errors) != len(expectedErrors) {
+		t.Errorf("expected %d errors, got %q", len(expectedErrors), report.Errors)
+	}
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	// Reset viper for clean test
+	viper.Reset()
+	Init()
+
+	viper.Set("sonarr", []map[string]interface{}{
+		{"name": "main", "baseurl": "http://localhost:8989", "apikey": "test-sonarr-key"},
+		{"name": "4k", "baseurl": "https://sonarr.example.com", "apikey": "test-sonarr-4k-key"},
+	})
+	viper.Set("radarr", []map[string]interface{}{
+		{"name": "main", "baseurl": "http://localhost:7878", "apikey": "test-radarr-key"},
+	})
+
+	report := Validate()
+	if len(report.Errors) != 0 || len(report.Warnings) != 0 {
+		t.Errorf("expected no problems, got errors %q and warnings %q", report.Errors, report.Warnings)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      map[string]any
+		expectedError string
+	}{
+		{name: "valid", settings: map[string]any{"batchsize": 5}},
+		{name: "warnings only", settings: map[string]any{"batchsize": 0, "intervall": "2h"}},
+		{name: "negative batch size", settings: map[string]any{"batchsize": -2}, expectedError: "Invalid batch size"},
+		{name: "zero interval", settings: map[string]any{"interval": "0s"}, expectedError: "Invalid interval"},
+		{
+			name: "duplicate names",
+			settings: map[string]any{"sonarr": []map[string]interface{}{
+				{"name": "main", "baseurl": "http://localhost:8989", "apikey": "test-sonarr-key"},
+				{"name": "main", "baseurl": "http://localhost:8990", "apikey": "test-sonarr-key"},
+			}},
+			expectedError: "defined more than once",
+		},
+		{
+			name: "malformed baseurl",
+			settings: map[string]any{"radarr": []map[string]interface{}{
+				{"name": "main", "baseurl": "localhost:7878", "apikey": "test-radarr-key"},
+			}},
+			expectedError: "malformed baseurl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset viper for clean test
+			viper.Reset()
+			Init()
+			for key, value := range tt.settings {
+				viper.Set(key, value)
+			}
+
+			config, err := check()
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if config.BatchSize != viper.GetInt("batchsize") {
+					t.Errorf("expected the configuration to be returned, got %+v", config)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCheckBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL     string
+		expectError bool
+	}{
+		{baseURL: "http://localhost:8989"},
+		{baseURL: "https://example.com/sonarr"},
+		{baseURL: "localhost:8989", expectError: true},
+		{baseURL: "ftp://localhost", expectError: true},
+		{baseURL: "http://", expectError: true},
+		{baseURL: "http://localhost:8989?apikey=x", expectError: true},
+		{baseURL: "http://local host", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			err := checkBaseURL(tt.baseURL)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -5,3 +5,29 @@ const (
 	// DefaultSearchBatchSize is the default number of items to search for at once
 	DefaultSearchBatchSize = 10
 )
+
+// Selection modes decide which custom format scores count as low
+const (
+	// ModeThreshold flags files scoring below the configured threshold
+	ModeThreshold = "threshold"
+	// ModeMinFormatScore flags files scoring below their quality profile's minimum format score
+	ModeMinFormatScore = "minformatscore"
+	// ModeCutoffFormatScore flags files scoring below their quality profile's cutoff format score
+	ModeCutoffFormatScore = "cutoff"
+)
+
+// Order strategies decide which low score items are searched first
+const (
+	// OrderDefault walks the library in ID order, resuming where the last run stopped
+	OrderDefault = "default"
+	// OrderLowestScore searches the worst scoring files first
+	OrderLowestScore = "lowest-score"
+	// OrderOldestFile searches the files imported longest ago first
+	OrderOldestFile = "oldest-file"
+	// OrderNewest searches the most recently aired or released items first
+	OrderNewest = "newest"
+	// OrderRandom searches items in random order
+	OrderRandom = "random"
+	// OrderRoundRobin takes one episode from each series (or album/book from each artist/author) in turn
+	OrderRoundRobin = "round-robin"
+)
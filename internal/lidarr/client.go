@@ -0,0 +1,76 @@
+package lidarr
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+
+	"score-checker/internal/arr"
+	"score-checker/internal/types"
+)
+
+// Client handles API interactions with Lidarr
+type Client struct {
+	*arr.Client
+}
+
+// NewClient creates a new Lidarr API client
+func NewClient(config types.ServiceConfig) *Client {
+	return &Client{Client: arr.NewClient(config, arr.APIv1)}
+}
+
+// GetArtists fetches all artists from Lidarr
+func (c *Client) GetArtists(ctx context.Context) ([]types.Artist, error) {
+	artists, err := arr.Collect(c.StreamArtists(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("fetching artists: %w", err)
+	}
+
+	return artists, nil
+}
+
+// StreamArtists streams all artists from Lidarr, decoding them as the response is read
+func (c *Client) StreamArtists(ctx context.Context) iter.Seq2[types.Artist, error] {
+	return arr.Stream[types.Artist](ctx, c.Client, "/artist", nil)
+}
+
+// GetAlbums fetches the albums of a specific artist
+func (c *Client) GetAlbums(ctx context.Context, artistID int) ([]types.Album, error) {
+	params := url.Values{}
+	params.Set("artistId", strconv.Itoa(artistID))
+
+	var albums []types.Album
+	if err := c.Get(ctx, "/album", params, &albums); err != nil {
+		return nil, fmt.Errorf("fetching albums for artist %d: %w", artistID, err)
+	}
+
+	return albums, nil
+}
+
+// GetTrackFiles fetches the track files of a specific artist with their custom format scores
+func (c *Client) GetTrackFiles(ctx context.Context, artistID int) ([]types.TrackFile, error) {
+	params := url.Values{}
+	params.Set("artistId", strconv.Itoa(artistID))
+
+	var trackFiles []types.TrackFile
+	if err := c.Get(ctx, "/trackfile", params, &trackFiles); err != nil {
+		return nil, fmt.Errorf("fetching track files for artist %d: %w", artistID, err)
+	}
+
+	return trackFiles, nil
+}
+
+// TriggerAlbumSearch triggers a search for better versions of specific albums
+func (c *Client) TriggerAlbumSearch(ctx context.Context, albumIDs []int) (*types.CommandResponse, error) {
+	if len(albumIDs) == 0 {
+		return nil, fmt.Errorf("no album IDs provided")
+	}
+
+	// Lidarr uses "albumIds"
+	return c.Command(ctx, map[string]interface{}{
+		"name":     "AlbumSearch",
+		"albumIds": albumIDs,
+	})
+}
@@ -0,0 +1,251 @@
+package lidarr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"score-checker/internal/types"
+)
+
+func TestNewClient(t *testing.T) {
+	config := types.ServiceConfig{
+		Name:    "test",
+		BaseURL: "http://localhost:8686",
+		APIKey:  "test-api-key",
+	}
+
+	client := NewClient(config)
+
+	if client == nil {
+		t.Fatal("expected client to not be nil")
+	}
+	if client.Config().BaseURL != config.BaseURL {
+		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.Config().BaseURL)
+	}
+	if client.Config().APIKey != config.APIKey {
+		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.Config().APIKey)
+	}
+	if client.Client == nil {
+		t.Error("expected arr client to not be nil")
+	}
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(types.ServiceConfig{
+		Name:    "test",
+		BaseURL: server.URL,
+		APIKey:  "test-api-key",
+	})
+}
+
+func verifyRequest(t *testing.T, r *http.Request, expectedPath, expectedArtistID string) {
+	if r.Header.Get("X-Api-Key") != "test-api-key" {
+		t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
+	}
+	if r.URL.Path != expectedPath {
+		t.Errorf("expected path %q, got %q", expectedPath, r.URL.Path)
+	}
+	if artistID := r.URL.Query().Get("artistId"); artistID != expectedArtistID {
+		t.Errorf("expected artistId %q, got %q", expectedArtistID, artistID)
+	}
+}
+
+func TestGetArtists(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectedLen  int
+		expectError  bool
+	}{
+		{
+			name:         "successful response",
+			responseCode: http.StatusOK,
+			responseBody: `[
+				{"id": 1, "artistName": "Radiohead", "qualityProfileId": 1, "monitored": true, "tags": [2]},
+				{"id": 2, "artistName": "Portishead", "qualityProfileId": 2, "monitored": false}
+			]`,
+			expectedLen: 2,
+		},
+		{
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+		},
+		{
+			name:         "invalid json",
+			responseCode: http.StatusOK,
+			responseBody: `invalid json`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/artist", "")
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			})
+
+			artists, err := client.GetArtists(context.Background())
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(artists) != tt.expectedLen {
+				t.Fatalf("expected %d artists, got %d", tt.expectedLen, len(artists))
+			}
+			if artists[0].ArtistName != "Radiohead" || artists[0].QualityProfileID != 1 || !artists[0].Monitored {
+				t.Errorf("unexpected first artist: %+v", artists[0])
+			}
+			if len(artists[0].Tags) != 1 || artists[0].Tags[0] != 2 {
+				t.Errorf("expected first artist to have tag 2, got %v", artists[0].Tags)
+			}
+		})
+	}
+}
+
+func TestGetAlbums(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		verifyRequest(t, r, "/api/v1/album", "1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id": 11, "artistId": 1, "title": "OK Computer", "monitored": true, "releaseDate": "1997-05-21T00:00:00Z"}]`))
+	})
+
+	albums, err := client.GetAlbums(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(albums) != 1 {
+		t.Fatalf("expected 1 album, got %d", len(albums))
+	}
+	if albums[0].ID != 11 || albums[0].Title != "OK Computer" || !albums[0].Monitored {
+		t.Errorf("unexpected album: %+v", albums[0])
+	}
+	if albums[0].ReleaseDate.Year() != 1997 {
+		t.Errorf("expected release year 1997, got %d", albums[0].ReleaseDate.Year())
+	}
+}
+
+func TestGetTrackFiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectError  bool
+	}{
+		{
+			name:         "successful response",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 111, "artistId": 1, "albumId": 11, "customFormatScore": -10}]`,
+		},
+		{
+			name:         "not found",
+			responseCode: http.StatusNotFound,
+			responseBody: `{"error": "not found"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/trackfile", "1")
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			})
+
+			trackFiles, err := client.GetTrackFiles(context.Background(), 1)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(trackFiles) != 1 {
+				t.Fatalf("expected 1 track file, got %d", len(trackFiles))
+			}
+			if trackFiles[0].AlbumID != 11 || trackFiles[0].CustomFormatScore != -10 {
+				t.Errorf("unexpected track file: %+v", trackFiles[0])
+			}
+		})
+	}
+}
+
+func TestTriggerAlbumSearch(t *testing.T) {
+	tests := []struct {
+		name         string
+		albumIDs     []int
+		responseCode int
+		expectError  bool
+	}{
+		{name: "successful search trigger", albumIDs: []int{11, 12}, responseCode: http.StatusCreated},
+		{name: "successful search trigger with OK status", albumIDs: []int{11}, responseCode: http.StatusOK},
+		{name: "empty album IDs", albumIDs: []int{}, expectError: true},
+		{name: "server error", albumIDs: []int{11}, responseCode: http.StatusBadRequest, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/command", "")
+				if r.Method != "POST" {
+					t.Errorf("expected POST method, got %q", r.Method)
+				}
+
+				var cmdReq struct {
+					Name     string `json:"name"`
+					AlbumIDs []int  `json:"albumIds"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&cmdReq); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if cmdReq.Name != "AlbumSearch" {
+					t.Errorf("expected command name 'AlbumSearch', got %q", cmdReq.Name)
+				}
+				if len(cmdReq.AlbumIDs) != len(tt.albumIDs) {
+					t.Errorf("expected album IDs %v, got %v", tt.albumIDs, cmdReq.AlbumIDs)
+				}
+
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(`{"id": 456, "name": "AlbumSearch", "commandName": "AlbumSearch", "status": "queued"}`))
+			})
+
+			resp, err := client.TriggerAlbumSearch(context.Background(), tt.albumIDs)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.ID != 456 || resp.Name != "AlbumSearch" || resp.Status != "queued" {
+				t.Errorf("unexpected command response: %+v", resp)
+			}
+		})
+	}
+}
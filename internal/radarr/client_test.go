@@ -1,10 +1,10 @@
 package radarr
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 
 	"score-checker/internal/types"
@@ -22,17 +22,17 @@ func TestNewClient(t *testing.T) {
 	if client == nil {
 		t.Fatal("expected client to not be nil")
 	}
-	if client.config.Name != config.Name {
-		t.Errorf("expected config name %q, got %q", config.Name, client.config.Name)
+	if client.Config().Name != config.Name {
+		t.Errorf("expected config name %q, got %q", config.Name, client.Config().Name)
 	}
-	if client.config.BaseURL != config.BaseURL {
-		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.config.BaseURL)
+	if client.Config().BaseURL != config.BaseURL {
+		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.Config().BaseURL)
 	}
-	if client.config.APIKey != config.APIKey {
-		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.config.APIKey)
+	if client.Config().APIKey != config.APIKey {
+		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.Config().APIKey)
 	}
-	if client.client == nil {
-		t.Error("expected http client to not be nil")
+	if client.Client == nil {
+		t.Error("expected arr client to not be nil")
 	}
 }
 
@@ -169,7 +169,7 @@ func TestGetMovies(t *testing.T) {
 			}
 			client := NewClient(config)
 
-			movies, err := client.GetMovies()
+			movies, err := client.GetMovies(context.Background())
 
 			if tt.expectError {
 				if err == nil {
@@ -347,7 +347,7 @@ func TestTriggerMovieSearch(t *testing.T) {
 			}
 			client := NewClient(config)
 
-			resp, err := client.TriggerMovieSearch(tt.movieIDs)
+			resp, err := client.TriggerMovieSearch(context.Background(), tt.movieIDs)
 
 			if tt.expectError {
 				if err == nil {
@@ -370,33 +370,37 @@ func TestTriggerMovieSearch(t *testing.T) {
 	}
 }
 
-func TestMakeRequest(t *testing.T) {
+func TestGetQualityProfiles(t *testing.T) {
 	tests := []struct {
-		name         string
-		endpoint     string
-		responseCode int
-		responseBody string
-		expectError  bool
+		name             string
+		responseCode     int
+		responseBody     string
+		expectedProfiles []types.QualityProfile
+		expectError      bool
 	}{
 		{
-			name:         "successful request",
-			endpoint:     "/api/v3/movie",
+			name:         "successful response",
 			responseCode: http.StatusOK,
-			responseBody: `{"result": "success"}`,
-			expectError:  false,
+			responseBody: `[
+				{"id": 1, "name": "HD-1080p", "minFormatScore": 0, "cutoffFormatScore": 100},
+				{"id": 2, "name": "Ultra-HD", "minFormatScore": 500, "cutoffFormatScore": 2000}
+			]`,
+			expectedProfiles: []types.QualityProfile{
+				{ID: 1, Name: "HD-1080p", MinFormatScore: 0, CutoffFormatScore: 100},
+				{ID: 2, Name: "Ultra-HD", MinFormatScore: 500, CutoffFormatScore: 2000},
+			},
+			expectError: false,
 		},
 		{
-			name:         "not found",
-			endpoint:     "/api/v3/notfound",
-			responseCode: http.StatusNotFound,
-			responseBody: `{"error": "not found"}`,
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
 			expectError:  true,
 		},
 		{
-			name:         "unauthorized",
-			endpoint:     "/api/v3/movie",
-			responseCode: http.StatusUnauthorized,
-			responseBody: `{"error": "unauthorized"}`,
+			name:         "invalid json",
+			responseCode: http.StatusOK,
+			responseBody: `invalid json`,
 			expectError:  true,
 		},
 	}
@@ -404,23 +408,21 @@ func TestMakeRequest(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if !strings.HasSuffix(r.URL.Path, tt.endpoint) {
-					t.Errorf("expected endpoint to end with %q, got %q", tt.endpoint, r.URL.Path)
+				if r.URL.Path != "/api/v3/qualityprofile" {
+					t.Errorf("expected path '/api/v3/qualityprofile', got %q", r.URL.Path)
 				}
-
 				w.WriteHeader(tt.responseCode)
 				_, _ = w.Write([]byte(tt.responseBody))
 			}))
 			defer server.Close()
 
-			config := types.ServiceConfig{
+			client := NewClient(types.ServiceConfig{
 				Name:    "test",
 				BaseURL: server.URL,
 				APIKey:  "test-api-key",
-			}
-			client := NewClient(config)
+			})
 
-			body, err := client.makeRequest(tt.endpoint, nil)
+			profiles, err := client.GetQualityProfiles(context.Background())
 
 			if tt.expectError {
 				if err == nil {
@@ -434,9 +436,35 @@ func TestMakeRequest(t *testing.T) {
 				return
 			}
 
-			if string(body) != tt.responseBody {
-				t.Errorf("expected response body %q, got %q", tt.responseBody, string(body))
+			if len(profiles) != len(tt.expectedProfiles) {
+				t.Fatalf("expected %d profiles, got %d", len(tt.expectedProfiles), len(profiles))
+			}
+			for i, expected := range tt.expectedProfiles {
+				if profiles[i] != expected {
+					t.Errorf("profile[%d] expected %+v, got %+v", i, expected, profiles[i])
+				}
 			}
 		})
 	}
 }
+
+func TestGetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/tag" {
+			t.Errorf("expected path '/api/v3/tag', got %q", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id": 1, "label": "anime"}, {"id": 2, "label": "keep"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"})
+
+	tags, err := client.GetTags(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != (types.Tag{ID: 1, Label: "anime"}) || tags[1] != (types.Tag{ID: 2, Label: "keep"}) {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
@@ -0,0 +1,76 @@
+package readarr
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+
+	"score-checker/internal/arr"
+	"score-checker/internal/types"
+)
+
+// Client handles API interactions with Readarr
+type Client struct {
+	*arr.Client
+}
+
+// NewClient creates a new Readarr API client
+func NewClient(config types.ServiceConfig) *Client {
+	return &Client{Client: arr.NewClient(config, arr.APIv1)}
+}
+
+// GetAuthors fetches all authors from Readarr
+func (c *Client) GetAuthors(ctx context.Context) ([]types.Author, error) {
+	authors, err := arr.Collect(c.StreamAuthors(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("fetching authors: %w", err)
+	}
+
+	return authors, nil
+}
+
+// StreamAuthors streams all authors from Readarr, decoding them as the response is read
+func (c *Client) StreamAuthors(ctx context.Context) iter.Seq2[types.Author, error] {
+	return arr.Stream[types.Author](ctx, c.Client, "/author", nil)
+}
+
+// GetBooks fetches the books of a specific author
+func (c *Client) GetBooks(ctx context.Context, authorID int) ([]types.Book, error) {
+	params := url.Values{}
+	params.Set("authorId", strconv.Itoa(authorID))
+
+	var books []types.Book
+	if err := c.Get(ctx, "/book", params, &books); err != nil {
+		return nil, fmt.Errorf("fetching books for author %d: %w", authorID, err)
+	}
+
+	return books, nil
+}
+
+// GetBookFiles fetches the book files of a specific author with their custom format scores
+func (c *Client) GetBookFiles(ctx context.Context, authorID int) ([]types.BookFile, error) {
+	params := url.Values{}
+	params.Set("authorId", strconv.Itoa(authorID))
+
+	var bookFiles []types.BookFile
+	if err := c.Get(ctx, "/bookfile", params, &bookFiles); err != nil {
+		return nil, fmt.Errorf("fetching book files for author %d: %w", authorID, err)
+	}
+
+	return bookFiles, nil
+}
+
+// TriggerBookSearch triggers a search for better versions of specific books
+func (c *Client) TriggerBookSearch(ctx context.Context, bookIDs []int) (*types.CommandResponse, error) {
+	if len(bookIDs) == 0 {
+		return nil, fmt.Errorf("no book IDs provided")
+	}
+
+	// Readarr uses "bookIds"
+	return c.Command(ctx, map[string]interface{}{
+		"name":    "BookSearch",
+		"bookIds": bookIDs,
+	})
+}
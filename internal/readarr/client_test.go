@@ -0,0 +1,251 @@
+package readarr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"score-checker/internal/types"
+)
+
+func TestNewClient(t *testing.T) {
+	config := types.ServiceConfig{
+		Name:    "test",
+		BaseURL: "http://localhost:8787",
+		APIKey:  "test-api-key",
+	}
+
+	client := NewClient(config)
+
+	if client == nil {
+		t.Fatal("expected client to not be nil")
+	}
+	if client.Config().BaseURL != config.BaseURL {
+		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.Config().BaseURL)
+	}
+	if client.Config().APIKey != config.APIKey {
+		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.Config().APIKey)
+	}
+	if client.Client == nil {
+		t.Error("expected arr client to not be nil")
+	}
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(types.ServiceConfig{
+		Name:    "test",
+		BaseURL: server.URL,
+		APIKey:  "test-api-key",
+	})
+}
+
+func verifyRequest(t *testing.T, r *http.Request, expectedPath, expectedAuthorID string) {
+	if r.Header.Get("X-Api-Key") != "test-api-key" {
+		t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
+	}
+	if r.URL.Path != expectedPath {
+		t.Errorf("expected path %q, got %q", expectedPath, r.URL.Path)
+	}
+	if authorID := r.URL.Query().Get("authorId"); authorID != expectedAuthorID {
+		t.Errorf("expected authorId %q, got %q", expectedAuthorID, authorID)
+	}
+}
+
+func TestGetAuthors(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectedLen  int
+		expectError  bool
+	}{
+		{
+			name:         "successful response",
+			responseCode: http.StatusOK,
+			responseBody: `[
+				{"id": 1, "authorName": "Terry Pratchett", "qualityProfileId": 1, "monitored": true, "tags": [2]},
+				{"id": 2, "authorName": "Ursula K. Le Guin", "qualityProfileId": 2, "monitored": false}
+			]`,
+			expectedLen: 2,
+		},
+		{
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+		},
+		{
+			name:         "invalid json",
+			responseCode: http.StatusOK,
+			responseBody: `invalid json`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/author", "")
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			})
+
+			authors, err := client.GetAuthors(context.Background())
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(authors) != tt.expectedLen {
+				t.Fatalf("expected %d authors, got %d", tt.expectedLen, len(authors))
+			}
+			if authors[0].AuthorName != "Terry Pratchett" || authors[0].QualityProfileID != 1 || !authors[0].Monitored {
+				t.Errorf("unexpected first author: %+v", authors[0])
+			}
+			if len(authors[0].Tags) != 1 || authors[0].Tags[0] != 2 {
+				t.Errorf("expected first author to have tag 2, got %v", authors[0].Tags)
+			}
+		})
+	}
+}
+
+func TestGetBooks(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		verifyRequest(t, r, "/api/v1/book", "1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id": 11, "authorId": 1, "title": "Guards! Guards!", "monitored": true, "releaseDate": "1989-11-01T00:00:00Z"}]`))
+	})
+
+	books, err := client.GetBooks(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("expected 1 book, got %d", len(books))
+	}
+	if books[0].ID != 11 || books[0].Title != "Guards! Guards!" || !books[0].Monitored {
+		t.Errorf("unexpected book: %+v", books[0])
+	}
+	if books[0].ReleaseDate.Year() != 1989 {
+		t.Errorf("expected release year 1989, got %d", books[0].ReleaseDate.Year())
+	}
+}
+
+func TestGetBookFiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectError  bool
+	}{
+		{
+			name:         "successful response",
+			responseCode: http.StatusOK,
+			responseBody: `[{"id": 111, "authorId": 1, "bookId": 11, "customFormatScore": -10}]`,
+		},
+		{
+			name:         "not found",
+			responseCode: http.StatusNotFound,
+			responseBody: `{"error": "not found"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/bookfile", "1")
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			})
+
+			bookFiles, err := client.GetBookFiles(context.Background(), 1)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(bookFiles) != 1 {
+				t.Fatalf("expected 1 book file, got %d", len(bookFiles))
+			}
+			if bookFiles[0].BookID != 11 || bookFiles[0].CustomFormatScore != -10 {
+				t.Errorf("unexpected book file: %+v", bookFiles[0])
+			}
+		})
+	}
+}
+
+func TestTriggerBookSearch(t *testing.T) {
+	tests := []struct {
+		name         string
+		bookIDs      []int
+		responseCode int
+		expectError  bool
+	}{
+		{name: "successful search trigger", bookIDs: []int{11, 12}, responseCode: http.StatusCreated},
+		{name: "successful search trigger with OK status", bookIDs: []int{11}, responseCode: http.StatusOK},
+		{name: "empty book IDs", bookIDs: []int{}, expectError: true},
+		{name: "server error", bookIDs: []int{11}, responseCode: http.StatusBadRequest, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				verifyRequest(t, r, "/api/v1/command", "")
+				if r.Method != "POST" {
+					t.Errorf("expected POST method, got %q", r.Method)
+				}
+
+				var cmdReq struct {
+					Name    string `json:"name"`
+					BookIDs []int  `json:"bookIds"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&cmdReq); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if cmdReq.Name != "BookSearch" {
+					t.Errorf("expected command name 'BookSearch', got %q", cmdReq.Name)
+				}
+				if len(cmdReq.BookIDs) != len(tt.bookIDs) {
+					t.Errorf("expected book IDs %v, got %v", tt.bookIDs, cmdReq.BookIDs)
+				}
+
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(`{"id": 456, "name": "BookSearch", "commandName": "BookSearch", "status": "queued"}`))
+			})
+
+			resp, err := client.TriggerBookSearch(context.Background(), tt.bookIDs)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.ID != 456 || resp.Name != "BookSearch" || resp.Status != "queued" {
+				t.Errorf("unexpected command response: %+v", resp)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package sonarr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 
 	"score-checker/internal/types"
@@ -23,94 +23,17 @@ func TestNewClient(t *testing.T) {
 	if client == nil {
 		t.Fatal("expected client to not be nil")
 	}
-	if client.config.Name != config.Name {
-		t.Errorf("expected config name %q, got %q", config.Name, client.config.Name)
+	if client.Config().Name != config.Name {
+		t.Errorf("expected config name %q, got %q", config.Name, client.Config().Name)
 	}
-	if client.config.BaseURL != config.BaseURL {
-		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.config.BaseURL)
+	if client.Config().BaseURL != config.BaseURL {
+		t.Errorf("expected config baseurl %q, got %q", config.BaseURL, client.Config().BaseURL)
 	}
-	if client.config.APIKey != config.APIKey {
-		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.config.APIKey)
+	if client.Config().APIKey != config.APIKey {
+		t.Errorf("expected config apikey %q, got %q", config.APIKey, client.Config().APIKey)
 	}
-	if client.client == nil {
-		t.Error("expected http client to not be nil")
-	}
-}
-
-func TestMakeRequest(t *testing.T) {
-	tests := []struct {
-		name         string
-		endpoint     string
-		responseCode int
-		responseBody string
-		expectError  bool
-		errorMessage string
-	}{
-		{
-			name:         "successful request",
-			endpoint:     "/api/v3/series",
-			responseCode: http.StatusOK,
-			responseBody: `[{"id": 1, "title": "Test Series"}]`,
-			expectError:  false,
-		},
-		{
-			name:         "server error",
-			endpoint:     "/api/v3/series",
-			responseCode: http.StatusInternalServerError,
-			responseBody: `{"error": "internal server error"}`,
-			expectError:  true,
-			errorMessage: "API request failed with status 500",
-		},
-		{
-			name:         "not found error",
-			endpoint:     "/api/v3/series",
-			responseCode: http.StatusNotFound,
-			responseBody: `{"error": "not found"}`,
-			expectError:  true,
-			errorMessage: "API request failed with status 404",
-		},
-		{
-			name:         "unauthorized error",
-			endpoint:     "/api/v3/series",
-			responseCode: http.StatusUnauthorized,
-			responseBody: `{"error": "unauthorized"}`,
-			expectError:  true,
-			errorMessage: "API request failed with status 401",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(tt.responseCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
-
-			config := types.ServiceConfig{
-				Name:    "test",
-				BaseURL: server.URL,
-				APIKey:  "test-api-key",
-			}
-			client := NewClient(config)
-
-			body, err := client.makeRequest(tt.endpoint, nil)
-
-			if tt.expectError {
-				if err == nil {
-					t.Error("expected error but got none")
-				} else if !strings.Contains(err.Error(), tt.errorMessage) {
-					t.Errorf("expected error message to contain %q, got %q", tt.errorMessage, err.Error())
-				}
-			} else {
-				if err != nil {
-					t.Errorf("unexpected error: %v", err)
-				}
-				if string(body) != tt.responseBody {
-					t.Errorf("expected body %q, got %q", tt.responseBody, string(body))
-				}
-			}
-		})
+	if client.Client == nil {
+		t.Error("expected arr client to not be nil")
 	}
 }
 
@@ -183,7 +106,7 @@ func TestGetSeries(t *testing.T) {
 			}
 			client := NewClient(config)
 
-			series, err := client.GetSeries()
+			series, err := client.GetSeries(context.Background())
 
 			if tt.expectError {
 				if err == nil {
@@ -214,13 +137,13 @@ func TestGetSeries(t *testing.T) {
 	}
 }
 
-func verifyEpisodesRequest(t *testing.T, r *http.Request, expectedSeriesID int) {
+func verifyEpisodesRequest(t *testing.T, r *http.Request, expectedPath string, expectedSeriesID int) {
 	if r.Header.Get("X-Api-Key") != "test-api-key" {
 		t.Errorf("expected X-Api-Key header 'test-api-key', got %q", r.Header.Get("X-Api-Key"))
 	}
 
-	if r.URL.Path != "/api/v3/episode" {
-		t.Errorf("expected path '/api/v3/episode', got %q", r.URL.Path)
+	if r.URL.Path != expectedPath {
+		t.Errorf("expected path %q, got %q", expectedPath, r.URL.Path)
 	}
 
 	seriesID := r.URL.Query().Get("seriesId")
@@ -229,9 +152,9 @@ func verifyEpisodesRequest(t *testing.T, r *http.Request, expectedSeriesID int)
 		t.Errorf("expected seriesId query param %q, got %q", expectedSeriesIDStr, seriesID)
 	}
 
-	includeEpisodeFile := r.URL.Query().Get("includeEpisodeFile")
-	if includeEpisodeFile != "true" {
-		t.Errorf("expected includeEpisodeFile query param 'true', got %q", includeEpisodeFile)
+	// Scores come from the episode files, so the files aren't embedded
+	if r.URL.Query().Has("includeEpisodeFile") {
+		t.Errorf("expected no includeEpisodeFile query param, got %q", r.URL.Query().Get("includeEpisodeFile"))
 	}
 }
 
@@ -254,6 +177,9 @@ func validateEpisode(t *testing.T, episode, expected types.Episode, index int) {
 	if episode.HasFile != expected.HasFile {
 		t.Errorf("episode[%d] expected HasFile %v, got %v", index, expected.HasFile, episode.HasFile)
 	}
+	if episode.EpisodeFileID != expected.EpisodeFileID {
+		t.Errorf("episode[%d] expected EpisodeFileID %d, got %d", index, expected.EpisodeFileID, episode.EpisodeFileID)
+	}
 	validateEpisodeFile(t, episode.EpisodeFile, expected.EpisodeFile, index)
 }
 
@@ -295,10 +221,7 @@ func TestGetEpisodes(t *testing.T) {
 					"seasonNumber": 1,
 					"episodeNumber": 1,
 					"hasFile": true,
-					"episodeFile": {
-						"id": 201,
-						"customFormatScore": -10
-					}
+					"episodeFileId": 201
 				}
 			]`,
 			expectedEpisodes: []types.Episode{
@@ -309,10 +232,7 @@ func TestGetEpisodes(t *testing.T) {
 					SeasonNumber:  1,
 					EpisodeNumber: 1,
 					HasFile:       true,
-					EpisodeFile: &types.EpisodeFile{
-						ID:                201,
-						CustomFormatScore: -10,
-					},
+					EpisodeFileID: 201,
 				},
 			},
 			expectError: false,
@@ -330,7 +250,7 @@ func TestGetEpisodes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				verifyEpisodesRequest(t, r, tt.seriesID)
+				verifyEpisodesRequest(t, r, "/api/v3/episode", tt.seriesID)
 				w.WriteHeader(tt.responseCode)
 				_, _ = w.Write([]byte(tt.responseBody))
 			}))
@@ -343,7 +263,7 @@ func TestGetEpisodes(t *testing.T) {
 			}
 			client := NewClient(config)
 
-			episodes, err := client.GetEpisodes(tt.seriesID)
+			episodes, err := client.GetEpisodes(context.Background(), tt.seriesID)
 
 			if tt.expectError {
 				if err == nil {
@@ -427,6 +347,77 @@ func validateCommandResponse(t *testing.T, actual, expected *types.CommandRespon
 	}
 }
 
+func TestGetEpisodeFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		seriesID      int
+		responseCode  int
+		responseBody  string
+		expectedFiles []types.EpisodeFile
+		expectError   bool
+	}{
+		{
+			name:         "successful response",
+			seriesID:     1,
+			responseCode: http.StatusOK,
+			responseBody: `[
+				{"id": 201, "seriesId": 1, "customFormatScore": -10},
+				{"id": 202, "seriesId": 1, "customFormatScore": 5}
+			]`,
+			expectedFiles: []types.EpisodeFile{
+				{ID: 201, SeriesID: 1, CustomFormatScore: -10},
+				{ID: 202, SeriesID: 1, CustomFormatScore: 5},
+			},
+			expectError: false,
+		},
+		{
+			name:         "server error",
+			seriesID:     1,
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verifyEpisodesRequest(t, r, "/api/v3/episodefile", tt.seriesID)
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{
+				Name:    "test",
+				BaseURL: server.URL,
+				APIKey:  "test-api-key",
+			})
+
+			episodeFiles, err := client.GetEpisodeFiles(context.Background(), tt.seriesID)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(episodeFiles) != len(tt.expectedFiles) {
+				t.Fatalf("expected %d episode files, got %d", len(tt.expectedFiles), len(episodeFiles))
+			}
+			for i, expected := range tt.expectedFiles {
+				if episodeFiles[i] != expected {
+					t.Errorf("episodeFiles[%d] expected %+v, got %+v", i, expected, episodeFiles[i])
+				}
+			}
+		})
+	}
+}
+
 func TestTriggerEpisodeSearch(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -497,7 +488,7 @@ func TestTriggerEpisodeSearch(t *testing.T) {
 			}
 			client := NewClient(config)
 
-			resp, err := client.TriggerEpisodeSearch(tt.episodeIDs)
+			resp, err := client.TriggerEpisodeSearch(context.Background(), tt.episodeIDs)
 
 			if tt.expectError {
 				if err == nil {
@@ -519,3 +510,102 @@ func TestTriggerEpisodeSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestGetQualityProfiles(t *testing.T) {
+	tests := []struct {
+		name             string
+		responseCode     int
+		responseBody     string
+		expectedProfiles []types.QualityProfile
+		expectError      bool
+	}{
+		{
+			name:         "successful response",
+			responseCode: http.StatusOK,
+			responseBody: `[
+				{"id": 1, "name": "HD-1080p", "minFormatScore": 0, "cutoffFormatScore": 100},
+				{"id": 2, "name": "Ultra-HD", "minFormatScore": 500, "cutoffFormatScore": 2000}
+			]`,
+			expectedProfiles: []types.QualityProfile{
+				{ID: 1, Name: "HD-1080p", MinFormatScore: 0, CutoffFormatScore: 100},
+				{ID: 2, Name: "Ultra-HD", MinFormatScore: 500, CutoffFormatScore: 2000},
+			},
+			expectError: false,
+		},
+		{
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error": "internal server error"}`,
+			expectError:  true,
+		},
+		{
+			name:         "invalid json",
+			responseCode: http.StatusOK,
+			responseBody: `invalid json`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v3/qualityprofile" {
+					t.Errorf("expected path '/api/v3/qualityprofile', got %q", r.URL.Path)
+				}
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewClient(types.ServiceConfig{
+				Name:    "test",
+				BaseURL: server.URL,
+				APIKey:  "test-api-key",
+			})
+
+			profiles, err := client.GetQualityProfiles(context.Background())
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if len(profiles) != len(tt.expectedProfiles) {
+				t.Fatalf("expected %d profiles, got %d", len(tt.expectedProfiles), len(profiles))
+			}
+			for i, expected := range tt.expectedProfiles {
+				if profiles[i] != expected {
+					t.Errorf("profile[%d] expected %+v, got %+v", i, expected, profiles[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/tag" {
+			t.Errorf("expected path '/api/v3/tag', got %q", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id": 1, "label": "anime"}, {"id": 2, "label": "keep"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(types.ServiceConfig{Name: "test", BaseURL: server.URL, APIKey: "test-api-key"})
+
+	tags, err := client.GetTags(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != (types.Tag{ID: 1, Label: "anime"}) || tags[1] != (types.Tag{ID: 2, Label: "keep"}) {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
@@ -0,0 +1,201 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the state file kept next to score-checker.log
+const FileName = "score-checker-state.json"
+
+// data is the on-disk representation of the state file
+type data struct {
+	Checked  map[string][]int             `json:"checked,omitempty"`
+	Partial  map[string]partial           `json:"partial,omitempty"`
+	Searches map[string]map[int]time.Time `json:"searches,omitempty"`
+}
+
+// partial is a library entry a run stopped in before all of its items were
+// looked at, along with the items found in it so far
+type partial struct {
+	Entry int   `json:"entry"`
+	Items []int `json:"items"`
+}
+
+// Store persists information that has to survive between runs.
+// A nil *Store is valid and behaves as an empty store that never saves.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	data data
+}
+
+// Load reads the state file at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("reading state file: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &store.data); err != nil {
+		return store, fmt.Errorf("unmarshaling state file: %w", err)
+	}
+
+	return store, nil
+}
+
+// Save writes the state to disk, replacing the previous file atomically
+func (s *Store) Save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("replacing state file: %w", err)
+	}
+
+	return nil
+}
+
+// Checked returns the IDs of the items processed for key in the current
+// pass through the library
+func (s *Store) Checked(key string) map[int]bool {
+	checked := make(map[int]bool)
+	if s == nil {
+		return checked
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.data.Checked[key] {
+		checked[id] = true
+	}
+	return checked
+}
+
+// SetChecked records the IDs of the items processed for key in the current
+// pass through the library
+func (s *Store) SetChecked(key string, ids []int) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Checked == nil {
+		s.data.Checked = make(map[string][]int)
+	}
+	s.data.Checked[key] = slices.Sorted(slices.Values(ids))
+}
+
+// Partial returns the ID of the entry the previous run under key stopped in
+// before all of its items were looked at, or 0 if there is none, along with
+// the IDs of the items found in it so far
+func (s *Store) Partial(key string) (int, map[int]bool) {
+	items := make(map[int]bool)
+	if s == nil {
+		return 0, items
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.data.Partial[key]
+	for _, id := range p.Items {
+		items[id] = true
+	}
+	return p.Entry, items
+}
+
+// SetPartial records the entry a run under key stopped in and the IDs of the
+// items found in it so far. An entry ID of 0 clears it.
+func (s *Store) SetPartial(key string, entry int, items []int) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry == 0 {
+		delete(s.data.Partial, key)
+		return
+	}
+	if s.data.Partial == nil {
+		s.data.Partial = make(map[string]partial)
+	}
+	s.data.Partial[key] = partial{Entry: entry, Items: slices.Sorted(slices.Values(items))}
+}
+
+// LastSearched returns when a search was last triggered for item id under key
+func (s *Store) LastSearched(key string, id int) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	searchedAt, ok := s.data.Searches[key][id]
+	return searchedAt, ok
+}
+
+// RecordSearch records that searches were triggered for ids under key at the given time
+func (s *Store) RecordSearch(key string, ids []int, at time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Searches == nil {
+		s.data.Searches = make(map[string]map[int]time.Time)
+	}
+	if s.data.Searches[key] == nil {
+		s.data.Searches[key] = make(map[int]time.Time)
+	}
+	for _, id := range ids {
+		s.data.Searches[key][id] = at
+	}
+}
+
+// PruneSearches forgets searches under key that happened before the given time
+func (s *Store) PruneSearches(key string, before time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, searchedAt := range s.data.Searches[key] {
+		if searchedAt.Before(before) {
+			delete(s.data.Searches[key], id)
+		}
+	}
+	if len(s.data.Searches[key]) == 0 {
+		delete(s.data.Searches, key)
+	}
+}
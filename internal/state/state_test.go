@@ -0,0 +1,138 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if checked := store.Checked("sonarr/main"); len(checked) != 0 {
+		t.Errorf("expected empty store to have no checked items, got %v", checked)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.SetChecked("sonarr/main", []int{42, 3})
+	store.SetChecked("radarr/4k", []int{7})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error reloading state: %v", err)
+	}
+	if checked := reloaded.Checked("sonarr/main"); len(checked) != 2 || !checked[3] || !checked[42] {
+		t.Errorf("expected items 3 and 42 to be checked, got %v", checked)
+	}
+	if checked := reloaded.Checked("radarr/4k"); len(checked) != 1 || !checked[7] {
+		t.Errorf("expected item 7 to be checked, got %v", checked)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store, err := Load(path)
+	if err == nil {
+		t.Error("expected error for invalid state file")
+	}
+	if store == nil {
+		t.Fatal("expected an empty store to be returned alongside the error")
+	}
+	if checked := store.Checked("sonarr/main"); len(checked) != 0 {
+		t.Errorf("expected no checked items, got %v", checked)
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, _ := Load(path)
+
+	earlier := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(2 * time.Hour)
+	store.RecordSearch("sonarr/main", []int{101, 102}, earlier)
+	store.RecordSearch("sonarr/main", []int{103}, later)
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error reloading state: %v", err)
+	}
+
+	searchedAt, ok := store.LastSearched("sonarr/main", 101)
+	if !ok || !searchedAt.Equal(earlier) {
+		t.Errorf("expected episode 101 searched at %v, got %v (found: %v)", earlier, searchedAt, ok)
+	}
+	if _, ok := store.LastSearched("radarr/main", 101); ok {
+		t.Error("expected searches to be tracked per key")
+	}
+
+	store.PruneSearches("sonarr/main", earlier.Add(time.Hour))
+	if _, ok := store.LastSearched("sonarr/main", 101); ok {
+		t.Error("expected search for 101 to be pruned")
+	}
+	if _, ok := store.LastSearched("sonarr/main", 103); !ok {
+		t.Error("expected search for 103 to be kept")
+	}
+}
+
+func TestPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, _ := Load(path)
+
+	if entry, items := store.Partial("sonarr/main"); entry != 0 || len(items) != 0 {
+		t.Errorf("expected no partial entry, got %d with %v", entry, items)
+	}
+
+	store.SetPartial("sonarr/main", 1, []int{102, 101})
+	if err := store.Save(); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error reloading state: %v", err)
+	}
+	if entry, items := store.Partial("sonarr/main"); entry != 1 || len(items) != 2 || !items[101] || !items[102] {
+		t.Errorf("expected entry 1 with items 101 and 102, got %d with %v", entry, items)
+	}
+
+	store.SetPartial("sonarr/main", 0, nil)
+	if entry, _ := store.Partial("sonarr/main"); entry != 0 {
+		t.Errorf("expected the partial entry to be cleared, got %d", entry)
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+
+	store.SetChecked("sonarr/main", []int{1})
+	if len(store.Checked("sonarr/main")) != 0 {
+		t.Error("expected nil store to have no checked items")
+	}
+	store.RecordSearch("sonarr/main", []int{1}, time.Now())
+	if _, ok := store.LastSearched("sonarr/main", 1); ok {
+		t.Error("expected nil store to have no search history")
+	}
+	if err := store.Save(); err != nil {
+		t.Errorf("expected nil store to save without error, got %v", err)
+	}
+}
@@ -1,9 +1,19 @@
 package testhelpers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"score-checker/internal/types"
@@ -29,33 +39,66 @@ func MockSonarrServer(t TestingInterface, series []types.Series, episodes map[in
 			}
 			_ = json.NewEncoder(w).Encode(series)
 
+		case "/api/v3/qualityprofile":
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CreateTestQualityProfiles())
+
+		case "/api/v3/tag":
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CreateTestTags())
+
 		case "/api/v3/episode":
 			if r.Method != "GET" {
 				w.WriteHeader(http.StatusMethodNotAllowed)
 				return
 			}
-			seriesIDStr := r.URL.Query().Get("seriesId")
-			if seriesIDStr == "" {
-				w.WriteHeader(http.StatusBadRequest)
+			seriesID, ok := mockSeriesID(w, r)
+			if !ok {
 				return
 			}
 
-			var seriesID int
-			switch seriesIDStr {
-			case "1":
-				seriesID = 1
-			case "2":
-				seriesID = 2
-			default:
-				w.WriteHeader(http.StatusNotFound)
+			// Like Sonarr, only embed the episode file when asked to
+			includeEpisodeFile := r.URL.Query().Get("includeEpisodeFile") == "true"
+			eps := []types.Episode{}
+			for _, ep := range episodes[seriesID] {
+				if ep.EpisodeFile != nil {
+					ep.EpisodeFileID = ep.EpisodeFile.ID
+				}
+				if !includeEpisodeFile {
+					ep.EpisodeFile = nil
+				}
+				eps = append(eps, ep)
+			}
+			_ = json.NewEncoder(w).Encode(eps)
+
+		case "/api/v3/episodefile":
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			seriesID, ok := mockSeriesID(w, r)
+			if !ok {
 				return
 			}
 
-			if eps, ok := episodes[seriesID]; ok {
-				_ = json.NewEncoder(w).Encode(eps)
-			} else {
-				_ = json.NewEncoder(w).Encode([]types.Episode{})
+			episodeFiles := []types.EpisodeFile{}
+			seen := make(map[int]bool)
+			for _, ep := range episodes[seriesID] {
+				if !ep.HasFile || ep.EpisodeFile == nil || seen[ep.EpisodeFile.ID] {
+					continue
+				}
+				seen[ep.EpisodeFile.ID] = true
+				episodeFile := *ep.EpisodeFile
+				episodeFile.SeriesID = seriesID
+				episodeFiles = append(episodeFiles, episodeFile)
 			}
+			_ = json.NewEncoder(w).Encode(episodeFiles)
 
 		case "/api/v3/command":
 			if r.Method != "POST" {
@@ -75,6 +118,23 @@ func MockSonarrServer(t TestingInterface, series []types.Series, episodes map[in
 	}))
 }
 
+// mockSeriesID reads the seriesId query parameter of a mock Sonarr request,
+// writing an error response when it is missing or unknown
+func mockSeriesID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	switch r.URL.Query().Get("seriesId") {
+	case "":
+		w.WriteHeader(http.StatusBadRequest)
+		return 0, false
+	case "1":
+		return 1, true
+	case "2":
+		return 2, true
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return 0, false
+	}
+}
+
 // MockRadarrServer creates a mock Radarr server for testing
 func MockRadarrServer(t TestingInterface, movies []types.MovieWithFile, commandResponse *types.CommandResponse) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -88,6 +148,20 @@ func MockRadarrServer(t TestingInterface, movies []types.MovieWithFile, commandR
 			}
 			_ = json.NewEncoder(w).Encode(movies)
 
+		case "/api/v3/qualityprofile":
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CreateTestQualityProfiles())
+
+		case "/api/v3/tag":
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CreateTestTags())
+
 		case "/api/v3/command":
 			if r.Method != "POST" {
 				w.WriteHeader(http.StatusMethodNotAllowed)
@@ -106,11 +180,139 @@ func MockRadarrServer(t TestingInterface, movies []types.MovieWithFile, commandR
 	}))
 }
 
+// MockLidarrServer creates a mock Lidarr server for testing. Albums and
+// track files are keyed by artist ID.
+func MockLidarrServer(t TestingInterface, artists []types.Artist, albums map[int][]types.Album, trackFiles map[int][]types.TrackFile, commandResponse *types.CommandResponse) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != "GET" && r.URL.Path != "/api/v1/command" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/artist":
+			_ = json.NewEncoder(w).Encode(artists)
+
+		case "/api/v1/album", "/api/v1/trackfile":
+			artistID, err := strconv.Atoi(r.URL.Query().Get("artistId"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if r.URL.Path == "/api/v1/album" {
+				_ = json.NewEncoder(w).Encode(append([]types.Album{}, albums[artistID]...))
+			} else {
+				_ = json.NewEncoder(w).Encode(append([]types.TrackFile{}, trackFiles[artistID]...))
+			}
+
+		case "/api/v1/qualityprofile":
+			_ = json.NewEncoder(w).Encode(CreateTestQualityProfiles())
+
+		case "/api/v1/tag":
+			_ = json.NewEncoder(w).Encode(CreateTestTags())
+
+		case "/api/v1/command":
+			if r.Method != "POST" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if commandResponse != nil {
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(commandResponse)
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// MockReadarrServer creates a mock Readarr server for testing. Books and book
+// files are keyed by author ID.
+func MockReadarrServer(t TestingInterface, authors []types.Author, books map[int][]types.Book, bookFiles map[int][]types.BookFile, commandResponse *types.CommandResponse) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != "GET" && r.URL.Path != "/api/v1/command" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/author":
+			_ = json.NewEncoder(w).Encode(authors)
+
+		case "/api/v1/book", "/api/v1/bookfile":
+			authorID, err := strconv.Atoi(r.URL.Query().Get("authorId"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if r.URL.Path == "/api/v1/book" {
+				_ = json.NewEncoder(w).Encode(append([]types.Book{}, books[authorID]...))
+			} else {
+				_ = json.NewEncoder(w).Encode(append([]types.BookFile{}, bookFiles[authorID]...))
+			}
+
+		case "/api/v1/qualityprofile":
+			_ = json.NewEncoder(w).Encode(CreateTestQualityProfiles())
+
+		case "/api/v1/tag":
+			_ = json.NewEncoder(w).Encode(CreateTestTags())
+
+		case "/api/v1/command":
+			if r.Method != "POST" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if commandResponse != nil {
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(commandResponse)
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// MockStatusServer creates a mock server answering the system status and
+// health endpoints under apiBase, rejecting requests without apiKey
+func MockStatusServer(t TestingInterface, apiBase, apiKey string, status types.SystemStatus, health []types.HealthCheck) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Header.Get("X-Api-Key") != apiKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case apiBase + "/system/status":
+			_ = json.NewEncoder(w).Encode(status)
+
+		case apiBase + "/health":
+			_ = json.NewEncoder(w).Encode(health)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
 // CreateTestSeries creates test series data
 func CreateTestSeries() []types.Series {
 	return []types.Series{
-		{ID: 1, Title: "Breaking Bad"},
-		{ID: 2, Title: "Better Call Saul"},
+		{ID: 1, Title: "Breaking Bad", QualityProfileID: 1, Monitored: true},
+		{ID: 2, Title: "Better Call Saul", QualityProfileID: 2, Monitored: true},
 	}
 }
 
@@ -124,6 +326,7 @@ func CreateTestEpisodes() map[int][]types.Episode {
 				Title:         "Pilot",
 				SeasonNumber:  1,
 				EpisodeNumber: 1,
+				Monitored:     true,
 				HasFile:       true,
 				EpisodeFile: &types.EpisodeFile{
 					ID:                201,
@@ -136,6 +339,7 @@ func CreateTestEpisodes() map[int][]types.Episode {
 				Title:         "Cat's in the Bag...",
 				SeasonNumber:  1,
 				EpisodeNumber: 2,
+				Monitored:     true,
 				HasFile:       true,
 				EpisodeFile: &types.EpisodeFile{
 					ID:                202,
@@ -150,6 +354,7 @@ func CreateTestEpisodes() map[int][]types.Episode {
 				Title:         "Uno",
 				SeasonNumber:  1,
 				EpisodeNumber: 1,
+				Monitored:     true,
 				HasFile:       true,
 				EpisodeFile: &types.EpisodeFile{
 					ID:                301,
@@ -164,20 +369,24 @@ func CreateTestEpisodes() map[int][]types.Episode {
 func CreateTestMovies() []types.MovieWithFile {
 	return []types.MovieWithFile{
 		{
-			ID:      1,
-			Title:   "The Matrix",
-			Year:    1999,
-			HasFile: true,
+			ID:               1,
+			Title:            "The Matrix",
+			Year:             1999,
+			QualityProfileID: 1,
+			Monitored:        true,
+			HasFile:          true,
 			MovieFile: &types.MovieFile{
 				ID:                101,
 				CustomFormatScore: -15,
 			},
 		},
 		{
-			ID:      2,
-			Title:   "Inception",
-			Year:    2010,
-			HasFile: true,
+			ID:               2,
+			Title:            "Inception",
+			Year:             2010,
+			QualityProfileID: 2,
+			Monitored:        true,
+			HasFile:          true,
 			MovieFile: &types.MovieFile{
 				ID:                102,
 				CustomFormatScore: 10,
@@ -187,12 +396,99 @@ func CreateTestMovies() []types.MovieWithFile {
 			ID:        3,
 			Title:     "Interstellar",
 			Year:      2014,
+			Monitored: true,
 			HasFile:   false,
 			MovieFile: nil,
 		},
 	}
 }
 
+// CreateTestArtists creates test artist data
+func CreateTestArtists() []types.Artist {
+	return []types.Artist{
+		{ID: 1, ArtistName: "Radiohead", QualityProfileID: 1, Monitored: true},
+		{ID: 2, ArtistName: "Portishead", QualityProfileID: 2, Monitored: true},
+	}
+}
+
+// CreateTestAlbums creates test album data keyed by artist ID
+func CreateTestAlbums() map[int][]types.Album {
+	return map[int][]types.Album{
+		1: {
+			{ID: 11, ArtistID: 1, Title: "OK Computer", Monitored: true},
+			{ID: 12, ArtistID: 1, Title: "Kid A", Monitored: true},
+		},
+		2: {
+			{ID: 21, ArtistID: 2, Title: "Dummy", Monitored: true},
+		},
+	}
+}
+
+// CreateTestTrackFiles creates test track file data keyed by artist ID
+func CreateTestTrackFiles() map[int][]types.TrackFile {
+	return map[int][]types.TrackFile{
+		1: {
+			{ID: 111, ArtistID: 1, AlbumID: 11, CustomFormatScore: -10},
+			{ID: 112, ArtistID: 1, AlbumID: 11, CustomFormatScore: -30},
+			{ID: 121, ArtistID: 1, AlbumID: 12, CustomFormatScore: 5},
+		},
+		2: {
+			{ID: 211, ArtistID: 2, AlbumID: 21, CustomFormatScore: -5},
+		},
+	}
+}
+
+// CreateTestAuthors creates test author data
+func CreateTestAuthors() []types.Author {
+	return []types.Author{
+		{ID: 1, AuthorName: "Terry Pratchett", QualityProfileID: 1, Monitored: true},
+		{ID: 2, AuthorName: "Ursula K. Le Guin", QualityProfileID: 2, Monitored: true},
+	}
+}
+
+// CreateTestBooks creates test book data keyed by author ID
+func CreateTestBooks() map[int][]types.Book {
+	return map[int][]types.Book{
+		1: {
+			{ID: 11, AuthorID: 1, Title: "Guards! Guards!", Monitored: true},
+			{ID: 12, AuthorID: 1, Title: "Small Gods", Monitored: true},
+		},
+		2: {
+			{ID: 21, AuthorID: 2, Title: "A Wizard of Earthsea", Monitored: true},
+		},
+	}
+}
+
+// CreateTestBookFiles creates test book file data keyed by author ID
+func CreateTestBookFiles() map[int][]types.BookFile {
+	return map[int][]types.BookFile{
+		1: {
+			{ID: 111, AuthorID: 1, BookID: 11, CustomFormatScore: -10},
+			{ID: 112, AuthorID: 1, BookID: 11, CustomFormatScore: -30},
+			{ID: 121, AuthorID: 1, BookID: 12, CustomFormatScore: 5},
+		},
+		2: {
+			{ID: 211, AuthorID: 2, BookID: 21, CustomFormatScore: -5},
+		},
+	}
+}
+
+// CreateTestQualityProfiles creates test quality profile data
+func CreateTestQualityProfiles() []types.QualityProfile {
+	return []types.QualityProfile{
+		{ID: 1, Name: "HD-1080p", MinFormatScore: 0, CutoffFormatScore: 10},
+		{ID: 2, Name: "Anime", MinFormatScore: -10, CutoffFormatScore: 100},
+	}
+}
+
+// CreateTestTags creates test tag data
+func CreateTestTags() []types.Tag {
+	return []types.Tag{
+		{ID: 1, Label: "anime"},
+		{ID: 2, Label: "keep"},
+	}
+}
+
 // CreateTestConfig creates a test configuration
 func CreateTestConfig() types.Config {
 	return types.Config{
@@ -210,6 +506,20 @@ func CreateTestConfig() types.Config {
 				APIKey:  "test-radarr-key",
 			},
 		},
+		LidarrInstances: []types.ServiceConfig{
+			{
+				Name:    "test-lidarr",
+				BaseURL: "http://localhost:8686",
+				APIKey:  "test-lidarr-key",
+			},
+		},
+		ReadarrInstances: []types.ServiceConfig{
+			{
+				Name:    "test-readarr",
+				BaseURL: "http://localhost:8787",
+				APIKey:  "test-readarr-key",
+			},
+		},
 		TriggerSearch: false,
 		BatchSize:     5,
 		Interval:      time.Hour,
@@ -226,3 +536,49 @@ func CreateTestCommandResponse() *types.CommandResponse {
 		Status:      "queued",
 	}
 }
+
+// WriteServerCA writes the certificate of a TLS test server to dir as a PEM
+// file, for use as a custom CA
+func WriteServerCA(t TestingInterface, dir string, server *httptest.Server) string {
+	t.Helper()
+	return writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", server.Certificate().Raw)
+}
+
+// WriteTestCertificate writes a self-signed client certificate and its key to
+// dir as PEM files
+func WriteTestCertificate(t TestingInterface, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "score-checker"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", der)
+	keyFile = writePEM(t, filepath.Join(dir, "client-key.pem"), "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t TestingInterface, path, blockType string, der []byte) string {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
@@ -36,6 +36,39 @@ func TestMockSonarrServer(t *testing.T) {
 		t.Errorf("expected status 200, got %d", resp.StatusCode)
 	}
 
+	// Test episode files endpoint
+	resp, err = http.Get(server.URL + "/api/v3/episodefile?seriesId=1")
+	if err != nil {
+		t.Fatalf("failed to get episode files: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test quality profile endpoint
+	resp, err = http.Get(server.URL + "/api/v3/qualityprofile")
+	if err != nil {
+		t.Fatalf("failed to get quality profiles: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test tag endpoint
+	resp, err = http.Get(server.URL + "/api/v3/tag")
+	if err != nil {
+		t.Fatalf("failed to get tags: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
 	// Test command endpoint
 	resp, err = http.Post(server.URL+"/api/v3/command", "application/json", strings.NewReader(`{"name":"EpisodeSearch"}`))
 	if err != nil {
@@ -78,6 +111,104 @@ func TestMockRadarrServer(t *testing.T) {
 	}
 }
 
+func TestMockLidarrServer(t *testing.T) {
+	server := MockLidarrServer(t, CreateTestArtists(), CreateTestAlbums(), CreateTestTrackFiles(), CreateTestCommandResponse())
+	defer server.Close()
+
+	// Test artists endpoint
+	resp, err := http.Get(server.URL + "/api/v1/artist")
+	if err != nil {
+		t.Fatalf("failed to get artists: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test track files endpoint
+	resp, err = http.Get(server.URL + "/api/v1/trackfile?artistId=1")
+	if err != nil {
+		t.Fatalf("failed to get track files: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test missing artistId
+	resp, err = http.Get(server.URL + "/api/v1/album")
+	if err != nil {
+		t.Fatalf("failed to get albums: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	// Test command endpoint
+	resp, err = http.Post(server.URL+"/api/v1/command", "application/json", strings.NewReader(`{"name":"AlbumSearch"}`))
+	if err != nil {
+		t.Fatalf("failed to post command: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", resp.StatusCode)
+	}
+}
+
+func TestMockReadarrServer(t *testing.T) {
+	server := MockReadarrServer(t, CreateTestAuthors(), CreateTestBooks(), CreateTestBookFiles(), CreateTestCommandResponse())
+	defer server.Close()
+
+	// Test authors endpoint
+	resp, err := http.Get(server.URL + "/api/v1/author")
+	if err != nil {
+		t.Fatalf("failed to get authors: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test book files endpoint
+	resp, err = http.Get(server.URL + "/api/v1/bookfile?authorId=1")
+	if err != nil {
+		t.Fatalf("failed to get book files: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Test missing authorId
+	resp, err = http.Get(server.URL + "/api/v1/book")
+	if err != nil {
+		t.Fatalf("failed to get books: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	// Test command endpoint
+	resp, err = http.Post(server.URL+"/api/v1/command", "application/json", strings.NewReader(`{"name":"BookSearch"}`))
+	if err != nil {
+		t.Fatalf("failed to post command: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", resp.StatusCode)
+	}
+}
+
 func TestCreateTestSeries(t *testing.T) {
 	series := CreateTestSeries()
 
@@ -142,6 +273,48 @@ func TestCreateTestMovies(t *testing.T) {
 	}
 }
 
+func TestCreateTestTrackFiles(t *testing.T) {
+	albums := CreateTestAlbums()
+
+	for artistID, trackFiles := range CreateTestTrackFiles() {
+		for _, tf := range trackFiles {
+			if tf.ArtistID != artistID {
+				t.Errorf("expected track file ArtistID %d to match map key %d", tf.ArtistID, artistID)
+			}
+			found := false
+			for _, album := range albums[artistID] {
+				if album.ID == tf.AlbumID {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected track file %d to belong to a test album of artist %d", tf.ID, artistID)
+			}
+		}
+	}
+}
+
+func TestCreateTestBookFiles(t *testing.T) {
+	books := CreateTestBooks()
+
+	for authorID, bookFiles := range CreateTestBookFiles() {
+		for _, tf := range bookFiles {
+			if tf.AuthorID != authorID {
+				t.Errorf("expected book file ArtistID %d to match map key %d", tf.AuthorID, authorID)
+			}
+			found := false
+			for _, book := range books[authorID] {
+				if book.ID == tf.BookID {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected book file %d to belong to a test book of author %d", tf.ID, authorID)
+			}
+		}
+	}
+}
+
 func TestCreateTestConfig(t *testing.T) {
 	config := CreateTestConfig()
 
@@ -1,28 +1,119 @@
 package types
 
-import "time"
+import (
+	"crypto/tls"
+	"net/url"
+	"regexp"
+	"time"
+)
 
 // ServiceConfig holds connection details for a single service
 type ServiceConfig struct {
-	Name    string
-	BaseURL string
-	APIKey  string
+	Name          string
+	BaseURL       string
+	APIKey        string
+	Threshold     int               // Files scoring below this are considered low
+	Mode          string            // How low scores are decided: threshold, minformatscore or cutoff
+	Cooldown      time.Duration     // Minimum time between searches for the same item
+	Order         string            // Which low score items are searched first
+	MonitoredOnly bool              // Skip unmonitored series, seasons, episodes and movies
+	Exclude       ExcludeConfig     // Series or movies that are never checked
+	IncludeTags   []string          // Only check items carrying one of these tag labels
+	ExcludeTags   []string          // Never check items carrying one of these tag labels
+	Workers       int               // How many requests for the instance's items run at once
+	Retry         RetryConfig       // How failed API requests are retried
+	RateLimit     RateLimitConfig   // How fast requests are sent to the instance
+	GracePeriod   time.Duration     // How long requests underway may take to finish once shutting down
+	Transport     TransportConfig   // How the instance is connected to
+	BasicAuth     *BasicAuth        // Credentials for a reverse proxy in front of the instance, nil for none
+	Headers       map[string]string // Extra headers sent with every request
+	APIKeyInQuery bool              // Send the API key as the apikey query parameter instead of a header
+}
+
+// BasicAuth holds HTTP basic auth credentials
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// TransportConfig controls the HTTP connections made to an instance
+type TransportConfig struct {
+	Timeout time.Duration // Limit for a whole request including reading the response, or for each read of a streamed library, 0 for none
+	TLS     *tls.Config   // Custom CA, client certificate or verification settings, nil for the defaults
+	Proxy   *url.URL      // Proxy requests are sent through, nil to use the proxy environment variables
+}
+
+// RateLimitConfig limits how fast requests are sent to an instance
+type RateLimitConfig struct {
+	RequestsPerSecond float64 // Average request rate, 0 for no limit
+	Burst             int     // Requests that may be sent at once before the rate applies
+}
+
+// RetryConfig controls how failed API requests are retried
+type RetryConfig struct {
+	Attempts int           // Total attempts per request, 1 disables retries
+	Delay    time.Duration // Backoff before the first retry, doubled for each one after
+	MaxDelay time.Duration // Upper bound for the backoff and for Retry-After waits
+}
+
+// ExcludeConfig lists series or movies that should never be checked
+type ExcludeConfig struct {
+	Titles   []string         // Exact titles, compared case-insensitively
+	Patterns []*regexp.Regexp // Regular expressions matched against titles
+	IDs      []int            // Sonarr/Radarr internal series or movie IDs
+	TvdbIDs  []int
+	TmdbIDs  []int
+	ImdbIDs  []string
 }
 
 // Config holds application configuration
 type Config struct {
-	SonarrInstances []ServiceConfig
-	RadarrInstances []ServiceConfig
-	TriggerSearch   bool          // Whether to actually trigger searches or just report
-	BatchSize       int           // Number of items to check per run
-	Interval        time.Duration // How often to run the check
-	LogLevel        string        // Logging level: ERROR, INFO, DEBUG, VERBOSE
+	SonarrInstances  []ServiceConfig
+	RadarrInstances  []ServiceConfig
+	LidarrInstances  []ServiceConfig
+	ReadarrInstances []ServiceConfig
+	TriggerSearch    bool            // Whether to actually trigger searches or just report
+	BatchSize        int             // Number of items to check per run
+	Interval         time.Duration   // How often to run the check
+	LogLevel         string          // Logging level: ERROR, INFO, DEBUG, VERBOSE
+	Threshold        int             // Default score threshold for instances that don't set their own
+	Mode             string          // Default selection mode for instances that don't set their own
+	StateFile        string          // Where progress between runs is persisted
+	Cooldown         time.Duration   // Default search cooldown for instances that don't set their own
+	Order            string          // Default order strategy for instances that don't set their own
+	MonitoredOnly    bool            // Default for skipping unmonitored items
+	Exclude          ExcludeConfig   // Exclusions applied to every instance
+	Concurrency      int             // How many instances are checked at once
+	Workers          int             // Default worker count for instances that don't set their own
+	Retry            RetryConfig     // Default retry policy for instances that don't set their own
+	RateLimit        RateLimitConfig // Default rate limit for instances that don't set their own
+	GracePeriod      time.Duration   // How long requests underway may take to finish once shutting down
+	Timeout          time.Duration   // Default request timeout for instances that don't set their own
 }
 
 // Series represents a Sonarr series (minimal fields needed)
 type Series struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
+	ID               int               `json:"id"`
+	Title            string            `json:"title"`
+	QualityProfileID int               `json:"qualityProfileId"`
+	TvdbID           int               `json:"tvdbId"`
+	TmdbID           int               `json:"tmdbId"`
+	ImdbID           string            `json:"imdbId"`
+	Tags             []int             `json:"tags"`
+	Monitored        bool              `json:"monitored"`
+	Seasons          []Season          `json:"seasons"`
+	Statistics       *SeriesStatistics `json:"statistics"`
+}
+
+// SeriesStatistics holds the file counts Sonarr reports for a series
+type SeriesStatistics struct {
+	EpisodeFileCount int `json:"episodeFileCount"`
+}
+
+// Season represents the monitored state of a season within a series
+type Season struct {
+	SeasonNumber int  `json:"seasonNumber"`
+	Monitored    bool `json:"monitored"`
 }
 
 // Episode represents a Sonarr episode
@@ -32,14 +123,47 @@ type Episode struct {
 	Title         string       `json:"title"`
 	SeasonNumber  int          `json:"seasonNumber"`
 	EpisodeNumber int          `json:"episodeNumber"`
+	AirDateUTC    time.Time    `json:"airDateUtc"`
+	Monitored     bool         `json:"monitored"`
 	HasFile       bool         `json:"hasFile"`
+	EpisodeFileID int          `json:"episodeFileId"`
 	EpisodeFile   *EpisodeFile `json:"episodeFile"`
 }
 
 // EpisodeFile represents episode file info
 type EpisodeFile struct {
-	ID                int `json:"id"`
-	CustomFormatScore int `json:"customFormatScore"`
+	ID                int       `json:"id"`
+	SeriesID          int       `json:"seriesId"`
+	CustomFormatScore int       `json:"customFormatScore"`
+	DateAdded         time.Time `json:"dateAdded"`
+}
+
+// QualityProfile represents the custom format score limits of a quality profile
+type QualityProfile struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	MinFormatScore    int    `json:"minFormatScore"`
+	CutoffFormatScore int    `json:"cutoffFormatScore"`
+}
+
+// Tag represents a Sonarr/Radarr tag
+type Tag struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+}
+
+// SystemStatus represents the application details from /system/status
+type SystemStatus struct {
+	AppName string `json:"appName"`
+	Version string `json:"version"`
+}
+
+// HealthCheck represents a problem reported by an application's health checks
+type HealthCheck struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"` // ok, notice, warning or error
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl"`
 }
 
 // CommandRequest represents a command to be sent to Sonarr
@@ -72,17 +196,26 @@ type Movie struct {
 
 // MovieFile represents movie file info
 type MovieFile struct {
-	ID                int `json:"id"`
-	CustomFormatScore int `json:"customFormatScore"`
+	ID                int       `json:"id"`
+	CustomFormatScore int       `json:"customFormatScore"`
+	DateAdded         time.Time `json:"dateAdded"`
 }
 
 // MovieWithFile represents a movie with its file information
 type MovieWithFile struct {
-	ID        int        `json:"id"`
-	Title     string     `json:"title"`
-	Year      int        `json:"year"`
-	HasFile   bool       `json:"hasFile"`
-	MovieFile *MovieFile `json:"movieFile"`
+	ID               int        `json:"id"`
+	Title            string     `json:"title"`
+	Year             int        `json:"year"`
+	QualityProfileID int        `json:"qualityProfileId"`
+	TmdbID           int        `json:"tmdbId"`
+	ImdbID           string     `json:"imdbId"`
+	Tags             []int      `json:"tags"`
+	InCinemas        time.Time  `json:"inCinemas"`
+	PhysicalRelease  time.Time  `json:"physicalRelease"`
+	DigitalRelease   time.Time  `json:"digitalRelease"`
+	Monitored        bool       `json:"monitored"`
+	HasFile          bool       `json:"hasFile"`
+	MovieFile        *MovieFile `json:"movieFile"`
 }
 
 // LowScoreMovie represents a movie with a low custom format score
@@ -90,3 +223,73 @@ type LowScoreMovie struct {
 	Movie             MovieWithFile
 	CustomFormatScore int
 }
+
+// Artist represents a Lidarr artist (minimal fields needed)
+type Artist struct {
+	ID               int    `json:"id"`
+	ArtistName       string `json:"artistName"`
+	QualityProfileID int    `json:"qualityProfileId"`
+	Tags             []int  `json:"tags"`
+	Monitored        bool   `json:"monitored"`
+}
+
+// Album represents a Lidarr album
+type Album struct {
+	ID          int       `json:"id"`
+	ArtistID    int       `json:"artistId"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"releaseDate"`
+	Monitored   bool      `json:"monitored"`
+}
+
+// TrackFile represents Lidarr track file info
+type TrackFile struct {
+	ID                int       `json:"id"`
+	ArtistID          int       `json:"artistId"`
+	AlbumID           int       `json:"albumId"`
+	CustomFormatScore int       `json:"customFormatScore"`
+	DateAdded         time.Time `json:"dateAdded"`
+}
+
+// LowScoreAlbum represents an album with low scoring track files
+type LowScoreAlbum struct {
+	Artist            Artist
+	Album             Album
+	CustomFormatScore int         // Lowest score among the album's track files
+	TrackFiles        []TrackFile // Track files scoring below the limit
+}
+
+// Author represents a Readarr author (minimal fields needed)
+type Author struct {
+	ID               int    `json:"id"`
+	AuthorName       string `json:"authorName"`
+	QualityProfileID int    `json:"qualityProfileId"`
+	Tags             []int  `json:"tags"`
+	Monitored        bool   `json:"monitored"`
+}
+
+// Book represents a Readarr book
+type Book struct {
+	ID          int       `json:"id"`
+	AuthorID    int       `json:"authorId"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"releaseDate"`
+	Monitored   bool      `json:"monitored"`
+}
+
+// BookFile represents Readarr book file info
+type BookFile struct {
+	ID                int       `json:"id"`
+	AuthorID          int       `json:"authorId"`
+	BookID            int       `json:"bookId"`
+	CustomFormatScore int       `json:"customFormatScore"`
+	DateAdded         time.Time `json:"dateAdded"`
+}
+
+// LowScoreBook represents a book with low scoring book files
+type LowScoreBook struct {
+	Author            Author
+	Book              Book
+	CustomFormatScore int        // Lowest score among the book's files
+	BookFiles         []BookFile // Book files scoring below the limit
+}